@@ -15,10 +15,14 @@ provider "alicloudsecurity" {
   visionone_region = var.visionone_region
 }
 
+resource "alicloudsecurity_visionone_trust" "trust" {
+  oidc_fingerprints = var.visionone_oidc_fingerprints
+}
+
 locals {
-  alicloud_account_id = "__module_cam_account_id__" # obtained from the output of CAM module
-  alicloud_role_arn = "__module_cam_role_arn__" # obtained from the output of CAM module
-  alicloud_oidc_provider_id = "__module_cam_oidc_provider_id__" # obtained from the output of CAM module 
+  alicloud_account_id = alicloudsecurity_visionone_trust.trust.account_id
+  alicloud_role_arn = alicloudsecurity_visionone_trust.trust.role_arn
+  alicloud_oidc_provider_id = alicloudsecurity_visionone_trust.trust.oidc_provider_id
   alicloud_name = var.visionone_account_name
   alicloud_description = var.visionone_account_description
 }
@@ -67,6 +71,12 @@ variable "visionone_region" {
   default     = "us"
 }
 
+variable "visionone_oidc_fingerprints" {
+  description = "value of the fingerprints of the Vision One OIDC issuer certificate"
+  type        = list(string)
+  default     = ["__auto_fill_by_backend_"] # auto-fill default value by the backend
+}
+
 variable "visionone_account_name" {
   description = "value of the Vision One account name"
   type        = string
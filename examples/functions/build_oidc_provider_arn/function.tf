@@ -0,0 +1,4 @@
+# Build the ARN of the OIDC provider trusted by the role
+output "oidc_provider_arn" {
+  value = provider::alicloudsecurity::build_oidc_provider_arn("1234567890123456", "trendmicro-visionone")
+}
@@ -0,0 +1,4 @@
+# Build the ARN of the role assumed by VisionOne
+output "role_arn" {
+  value = provider::alicloudsecurity::build_role_arn("1234567890123456", "visionone")
+}
@@ -0,0 +1,4 @@
+# Read the account of a RAM role
+output "role_account_id" {
+  value = provider::alicloudsecurity::parse_arn("acs:ram::1234567890123456:role/visionone").account_id
+}
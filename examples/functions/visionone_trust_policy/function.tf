@@ -0,0 +1,4 @@
+# Render the trust policy of the RAM role assumed by VisionOne
+output "trust_policy" {
+  value = provider::alicloudsecurity::visionone_trust_policy("1234567890123456", "trendmicro-visionone", var.visionone_business_id, "us")
+}
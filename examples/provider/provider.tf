@@ -2,4 +2,15 @@
 provider "alicloudsecurity" {
   visionone_api_key = "your_api_key_here"
   visionone_region = "your_region_here"
+
+  alicloud {
+    region = "cn-hangzhou"
+
+    # Optional, assume a RAM role with the credential resolved from the
+    # environment, the ~/.aliyun/config.json profile or the ECS RAM role.
+    assume_role {
+      role_arn     = "acs:ram::123456789012:role/terraform"
+      session_name = "terraform"
+    }
+  }
 }
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Patterns of the parts of the ARNs built by the provider.
+var (
+	accountIdPattern        = regexp.MustCompile(`^\d{1,16}$`)
+	roleNamePattern         = regexp.MustCompile(`^[\w.-]{1,64}$`)
+	oidcProviderNamePattern = regexp.MustCompile(`^[\w.-]{1,128}$`)
+)
+
+// Arn is an Alibaba Cloud Resource Name, acs:<service>:<region>:<account>:<resource>. The
+// resource is made of a type and a name separated by a slash, such as role/<name>.
+type Arn struct {
+	Service      string // The service of the resource, such as ram.
+	Region       string // The region of the resource, empty for global services such as RAM.
+	AccountId    string // The ID of the account owning the resource.
+	ResourceType string // The type of the resource, such as role, empty if the resource has no slash.
+	ResourceName string // The name of the resource, which may contain slashes.
+}
+
+// String returns the ARN in its text form.
+func (a *Arn) String() string {
+	resource := a.ResourceName
+	if a.ResourceType != "" {
+		resource = a.ResourceType + "/" + a.ResourceName
+	}
+	return strings.Join([]string{"acs", a.Service, a.Region, a.AccountId, resource}, ":")
+}
+
+// ParseArn parses an Alibaba Cloud Resource Name.
+func ParseArn(arn string) (*Arn, error) {
+	parts := strings.SplitN(arn, ":", 5)
+	if len(parts) != 5 || parts[0] != "acs" {
+		return nil, fmt.Errorf("%q is not an Alibaba Cloud ARN, expected acs:<service>:<region>:<account>:<resource>", arn)
+	}
+	if parts[1] == "" {
+		return nil, fmt.Errorf("%q has no service", arn)
+	}
+	if parts[4] == "" {
+		return nil, fmt.Errorf("%q has no resource", arn)
+	}
+
+	parsed := &Arn{
+		Service:   parts[1],
+		Region:    parts[2],
+		AccountId: parts[3],
+	}
+	if resourceType, resourceName, ok := strings.Cut(parts[4], "/"); ok {
+		parsed.ResourceType = resourceType
+		parsed.ResourceName = resourceName
+	} else {
+		parsed.ResourceName = parts[4]
+	}
+	return parsed, nil
+}
+
+// BuildRoleArn returns the ARN of a RAM role of an AliCloud account.
+func BuildRoleArn(accountId, roleName string) string {
+	return (&Arn{Service: "ram", AccountId: accountId, ResourceType: "role", ResourceName: roleName}).String()
+}
+
+// ParseRoleArn returns the ID of the account and the name of the role of a RAM role ARN.
+func ParseRoleArn(roleArn string) (accountId, roleName string, err error) {
+	arn, err := ParseArn(roleArn)
+	if err != nil {
+		return "", "", err
+	}
+	if arn.Service != "ram" || arn.Region != "" || arn.ResourceType != "role" {
+		return "", "", fmt.Errorf("%q is not a RAM role ARN, expected acs:ram::<account>:role/<name>", roleArn)
+	}
+	if err := ValidateAccountId(arn.AccountId); err != nil {
+		return "", "", err
+	}
+	if err := ValidateRoleName(arn.ResourceName); err != nil {
+		return "", "", err
+	}
+	return arn.AccountId, arn.ResourceName, nil
+}
+
+// ValidateAccountId checks the ID of an AliCloud account.
+func ValidateAccountId(accountId string) error {
+	if !accountIdPattern.MatchString(accountId) {
+		return fmt.Errorf("%q is not an AliCloud account ID, expected 1 to 16 digits", accountId)
+	}
+	return nil
+}
+
+// ValidateRoleName checks the name of a RAM role.
+func ValidateRoleName(roleName string) error {
+	if !roleNamePattern.MatchString(roleName) {
+		return fmt.Errorf("%q is not a RAM role name, expected 1 to 64 letters, digits, periods, hyphens or underscores", roleName)
+	}
+	return nil
+}
+
+// ValidateOidcProviderName checks the name of an OIDC identity provider.
+func ValidateOidcProviderName(oidcProviderName string) error {
+	if !oidcProviderNamePattern.MatchString(oidcProviderName) {
+		return fmt.Errorf("%q is not an OIDC provider name, expected 1 to 128 letters, digits, periods, hyphens or underscores", oidcProviderName)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestParseArn(t *testing.T) {
+	tests := []struct {
+		arn      string
+		expected *Arn
+	}{
+		{
+			arn:      "acs:ram::1234567890123456:role/visionone",
+			expected: &Arn{Service: "ram", AccountId: "1234567890123456", ResourceType: "role", ResourceName: "visionone"},
+		},
+		{
+			arn:      "acs:ram::1234567890123456:oidc-provider/trendmicro-visionone",
+			expected: &Arn{Service: "ram", AccountId: "1234567890123456", ResourceType: "oidc-provider", ResourceName: "trendmicro-visionone"},
+		},
+		{
+			arn:      "acs:oss:cn-hangzhou:1234567890123456:bucket/logs/2025",
+			expected: &Arn{Service: "oss", Region: "cn-hangzhou", AccountId: "1234567890123456", ResourceType: "bucket", ResourceName: "logs/2025"},
+		},
+		{
+			arn:      "acs:ram::1234567890123456:root",
+			expected: &Arn{Service: "ram", AccountId: "1234567890123456", ResourceName: "root"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			arn, err := ParseArn(tt.arn)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *arn != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, arn)
+			}
+			if arn.String() != tt.arn {
+				t.Errorf("expected the ARN to format back to %s, got %s", tt.arn, arn.String())
+			}
+		})
+	}
+}
+
+func TestParseArnInvalid(t *testing.T) {
+	for _, arn := range []string{
+		"",
+		"arn:aws:iam::123456789012:role/visionone",
+		"acs:ram::1234567890123456",
+		"acs::cn-hangzhou:1234567890123456:role/visionone",
+		"acs:ram::1234567890123456:",
+	} {
+		if _, err := ParseArn(arn); err == nil {
+			t.Errorf("expected an error for %q, got nil", arn)
+		}
+	}
+}
+
+func TestBuildRoleArn(t *testing.T) {
+	roleArn := BuildRoleArn("1234567890123456", "visionone")
+	if roleArn != "acs:ram::1234567890123456:role/visionone" {
+		t.Errorf("unexpected role ARN %s", roleArn)
+	}
+	accountId, roleName, err := ParseRoleArn(roleArn)
+	if err != nil || accountId != "1234567890123456" || roleName != "visionone" {
+		t.Errorf("expected %s to parse back to its account and role, got %q, %q, %v", roleArn, accountId, roleName, err)
+	}
+}
+
+func TestParseRoleArn(t *testing.T) {
+	accountId, roleName, err := ParseRoleArn("acs:ram::1234567890123456:role/visionone-role")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accountId != "1234567890123456" || roleName != "visionone-role" {
+		t.Errorf("unexpected account %q and role %q", accountId, roleName)
+	}
+
+	for _, roleArn := range []string{
+		"",
+		"acs:ram::1234567890123456:user/visionone",
+		"acs:ram::account:role/visionone",
+		"acs:ram::1234567890123456:role/",
+		"acs:ram::1234567890123456:role/vision/one",
+		"acs:ram:cn-hangzhou:1234567890123456:role/visionone",
+		"acs:ecs::1234567890123456:role/visionone",
+		"acs:ram::1234567890123456:visionone",
+	} {
+		if _, _, err := ParseRoleArn(roleArn); err == nil {
+			t.Errorf("expected an error for %q", roleArn)
+		}
+	}
+}
+
+func TestValidateArnParts(t *testing.T) {
+	if err := ValidateAccountId("1234567890123456"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, accountId := range []string{"", "12345678901234567", "account"} {
+		if err := ValidateAccountId(accountId); err == nil {
+			t.Errorf("expected an error for account ID %q, got nil", accountId)
+		}
+	}
+
+	if err := ValidateRoleName("vision-one.role_1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateRoleName("vision one"); err == nil {
+		t.Error("expected an error for a role name with a space, got nil")
+	}
+
+	if err := ValidateOidcProviderName("trendmicro-visionone"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := ValidateOidcProviderName("trendmicro/visionone"); err == nil {
+		t.Error("expected an error for an OIDC provider name with a slash, got nil")
+	}
+}
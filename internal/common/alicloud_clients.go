@@ -3,46 +3,100 @@ package common
 import (
 	"context"
 	"fmt"
-	"os"
+	"strings"
+	"sync"
 
 	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
 	ram "github.com/alibabacloud-go/ram-20150501/v2/client"
 	sts "github.com/alibabacloud-go/sts-20150401/v2/client"
 	"github.com/alibabacloud-go/tea/tea"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/aliyun/credentials-go/credentials"
 )
 
 type AliCloudClients struct {
 	Sts *sts.Client
 	Ram *ram.Client
+	Ims *ImsClient
+
+	Config                    *AliCloudClientConfig // The configuration of the clients. Read from the environment variables if nil.
+	SkipCredentialsValidation bool                  // Whether to skip the GetCallerIdentity check when the clients are built.
+	Transport                 *TransportConfig      // The settings of the HTTP transport supported by the clients. The default settings are used if nil.
+
+	mu              sync.Mutex             // Guards the lazy build of the clients and the cached caller identity.
+	built           bool                   // Whether Build succeeded.
+	callerAccountId string                 // The account ID returned by GetCallerIdentity, empty until it is called.
+	credential      credentials.Credential // The credential shared by the clients, resolved by the first client built.
 }
 
 type AliCloudClientConfig struct {
-	AccessKey       string // Access Key ID
-	AccessKeySecret string // Access Key Secret
-	Region          string // Region ID
+	AccessKey       string                    // Access Key ID
+	AccessKeySecret string                    // Access Key Secret
+	SecurityToken   string                    // STS Security Token, used with a temporary Access Key
+	Region          string                    // Region ID
+	Profile         string                    // Name of the profile in the Alibaba Cloud CLI configuration file
+	ProfileFile     string                    // Path of the Alibaba Cloud CLI configuration file, ~/.aliyun/config.json by default
+	EcsRoleName     string                    // Name of the RAM role attached to the ECS instance
+	StsEndpoint     string                    // Endpoint of the STS API, sts.<region>.aliyuncs.com by default
+	RamEndpoint     string                    // Endpoint of the RAM API, ram.aliyuncs.com by default
+	ImsEndpoint     string                    // Endpoint of the IMS API, ims.aliyuncs.com by default
+	Oidc            *AliCloudOidcConfig       // RAM role assumed with an OIDC token
+	AssumeRole      *AliCloudAssumeRoleConfig // RAM role assumed with the resolved credential
 }
 
 func NewAliCloudClients() *AliCloudClients {
 	return &AliCloudClients{}
 }
 
+// Build builds the STS, RAM and IMS clients and verifies the credentials, unless
+// SkipCredentialsValidation is set. The clients are only built on the first successful call,
+// so resources call it on first use of the AliCloud APIs. It is safe for concurrent use.
 func (a *AliCloudClients) Build() (*AliCloudClients, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.built {
+		return a, nil
+	}
+
 	if _, err := a.BuildStsClient(context.Background(), ""); err != nil {
 		return nil, err
 	}
 	if _, err := a.BuildRamClient(context.Background(), ""); err != nil {
 		return nil, err
 	}
+	if _, err := a.BuildImsClient(context.Background(), ""); err != nil {
+		return nil, err
+	}
 
-	if err := a.verifyConfig(); err != nil {
+	if a.SkipCredentialsValidation {
+		LogInfo(context.Background(), "Skipping AliCloud credentials validation")
+	} else if err := a.verifyConfig(); err != nil {
 		return nil, err
 	}
 
-	tflog.Info(context.Background(), "AliCloud clients created successfully")
+	a.built = true
+	LogInfo(context.Background(), "AliCloud clients created successfully")
 	return a, nil
 }
 
+// CallerAccountId returns the ID of the AliCloud account of the credentials, building the
+// clients if needed. The result of GetCallerIdentity is cached for the provider process.
+func (a *AliCloudClients) CallerAccountId() (string, error) {
+	if _, err := a.Build(); err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.callerAccountId == "" {
+		if err := a.verifyConfig(); err != nil {
+			return "", err
+		}
+	}
+	return a.callerAccountId, nil
+}
+
 // Use sts client to verify the configuration
 func (a *AliCloudClients) verifyConfig() error {
 	var accountId string
@@ -55,7 +109,7 @@ func (a *AliCloudClients) verifyConfig() error {
 	if err != nil {
 		return fmt.Errorf("failed to get caller identity: %v", err)
 	}
-	if resp == nil {
+	if resp == nil || resp.Body == nil {
 		return fmt.Errorf("failed to get caller identity: response is nil")
 	} else {
 		accountId = tea.StringValue(resp.Body.AccountId)
@@ -64,11 +118,12 @@ func (a *AliCloudClients) verifyConfig() error {
 	if accountId == "" {
 		return fmt.Errorf("failed to get account ID: response is empty")
 	} else {
-		tflog.Info(context.Background(), "STS client configuration verified successfully", map[string]any{
+		LogInfo(context.Background(), "STS client configuration verified successfully", map[string]any{
 			"accountId": accountId,
 		})
 	}
 
+	a.callerAccountId = accountId
 	return nil
 }
 
@@ -78,14 +133,17 @@ func (a *AliCloudClients) BuildStsClient(ctx context.Context, region string) (*s
 	}
 
 	// Configure the shared configuration
-	config, err := obtainDefaultConfig()
+	config, err := a.obtainConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to obtain default config: %v", err)
+		return nil, fmt.Errorf("failed to obtain config: %v", err)
 	}
 	if region != "" {
 		config.RegionId = tea.String(region)
 	}
 	config.Endpoint = tea.String(fmt.Sprintf("sts.%s.aliyuncs.com", *config.RegionId))
+	if endpoint := a.clientConfig().StsEndpoint; endpoint != "" {
+		setEndpoint(config, endpoint)
+	}
 
 	// Initialize STS client
 	client, err := sts.NewClient(config)
@@ -97,8 +155,9 @@ func (a *AliCloudClients) BuildStsClient(ctx context.Context, region string) (*s
 	if client == nil {
 		return nil, fmt.Errorf("failed to create STS client")
 	} else {
-		tflog.Info(ctx, "Alicloud STS client created successfully", map[string]any{
-			"config": config,
+		LogInfo(ctx, "Alicloud STS client created successfully", map[string]any{
+			"region":   tea.StringValue(config.RegionId),
+			"endpoint": tea.StringValue(config.Endpoint),
 		})
 		a.Sts = client
 	}
@@ -113,17 +172,21 @@ func (a *AliCloudClients) BuildRamClient(
 	}
 
 	// Configure the shared configuration
-	config, err := obtainDefaultConfig()
+	config, err := a.obtainConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to obtain default config: %v", err)
+		return nil, fmt.Errorf("failed to obtain config: %v", err)
 	}
 	if region != "" {
 		config.RegionId = tea.String(region)
 	}
 	// Initialize RAM client
 	config.Endpoint = tea.String("ram.aliyuncs.com")
-	tflog.Info(ctx, "Creating Alicloud Resource Access Management client", map[string]any{
-		"config": config,
+	if endpoint := a.clientConfig().RamEndpoint; endpoint != "" {
+		setEndpoint(config, endpoint)
+	}
+	LogInfo(ctx, "Creating Alicloud Resource Access Management client", map[string]any{
+		"region":   tea.StringValue(config.RegionId),
+		"endpoint": tea.StringValue(config.Endpoint),
 	})
 	client, err := ram.NewClient(config)
 	if err != nil {
@@ -133,26 +196,85 @@ func (a *AliCloudClients) BuildRamClient(
 	if client == nil {
 		return nil, fmt.Errorf("failed to create RAM client")
 	} else {
-		tflog.Info(ctx, "Alicloud Resource Access Management client created successfully")
+		LogInfo(ctx, "Alicloud Resource Access Management client created successfully")
 	}
 
 	a.Ram = client
 	return a.Ram, nil
 }
 
-// Obtain the default configuration for the AliCloud client from the environment variables.
-func obtainDefaultConfig() (*openapi.Config, error) {
-	alicloudAccessKey := os.Getenv("ALICLOUD_ACCESS_KEY")
-	alicloudAccessSecret := os.Getenv("ALICLOUD_ACCESS_SECRET")
-	alicloudRegion := os.Getenv("ALICLOUD_REGION")
+func (a *AliCloudClients) BuildImsClient(ctx context.Context, region string) (*ImsClient, error) {
+	if a.Ims != nil {
+		return a.Ims, nil
+	}
+
+	// Configure the shared configuration
+	config, err := a.obtainConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain config: %v", err)
+	}
+	if region != "" {
+		config.RegionId = tea.String(region)
+	}
+	// Initialize IMS client
+	if endpoint := a.clientConfig().ImsEndpoint; endpoint != "" {
+		setEndpoint(config, endpoint)
+	}
+	client, err := NewImsClient(config)
+	if err != nil {
+		return nil, err
+	}
+	LogInfo(ctx, "Alicloud Identity Management Service client created successfully", map[string]any{
+		"region":   tea.StringValue(config.RegionId),
+		"endpoint": tea.StringValue(config.Endpoint),
+	})
+
+	a.Ims = client
+	return a.Ims, nil
+}
+
+// clientConfig returns the configuration of the clients, read from the environment variables
+// if it is not set.
+func (a *AliCloudClients) clientConfig() *AliCloudClientConfig {
+	if a.Config == nil {
+		return NewAliCloudClientConfigFromEnv()
+	}
+	return a.Config
+}
+
+// setEndpoint sets the endpoint of the client configuration. The endpoint is either a host or
+// a URL, in which case its scheme is used as the protocol of the client.
+func setEndpoint(config *openapi.Config, endpoint string) {
+	if scheme, host, ok := strings.Cut(endpoint, "://"); ok {
+		config.Protocol = tea.String(scheme)
+		endpoint = strings.TrimSuffix(host, "/")
+	}
+	config.Endpoint = tea.String(endpoint)
+}
+
+// Obtain the configuration for the AliCloud client, resolving the credential from the
+// configured sources on the first call.
+func (a *AliCloudClients) obtainConfig() (*openapi.Config, error) {
+	clientConfig := a.clientConfig()
 
-	if alicloudAccessKey == "" || alicloudAccessSecret == "" || alicloudRegion == "" {
-		return nil, fmt.Errorf("ALICLOUD_ACCESS_KEY, ALICLOUD_ACCESS_SECRET, and ALICLOUD_REGION environment variables must be set")
+	if clientConfig.Region == "" {
+		return nil, fmt.Errorf("the AliCloud region must be set in the provider alicloud block or the ALICLOUD_REGION environment variable")
 	}
 
-	return &openapi.Config{
-		AccessKeyId:     tea.String(alicloudAccessKey),
-		AccessKeySecret: tea.String(alicloudAccessSecret),
-		RegionId:        tea.String(alicloudRegion),
-	}, nil
+	// The clients share the credential, so that the ECS metadata or OIDC round-trips of its
+	// provider only run once
+	if a.credential == nil {
+		credential, err := newAliCloudCredential(clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve AliCloud credentials: %v", err)
+		}
+		a.credential = credential
+	}
+
+	config := &openapi.Config{
+		Credential: a.credential,
+		RegionId:   tea.String(clientConfig.Region),
+	}
+	a.Transport.applyToOpenApiConfig(config)
+	return config, nil
 }
@@ -0,0 +1,51 @@
+package common
+
+import "testing"
+
+func TestAliCloudClientsBuildSkipsCredentialsValidation(t *testing.T) {
+	clients := &AliCloudClients{
+		Config:                    &AliCloudClientConfig{AccessKey: "ak", AccessKeySecret: "secret", Region: "cn-hangzhou"},
+		SkipCredentialsValidation: true,
+	}
+
+	if _, err := clients.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clients.Sts == nil || clients.Ram == nil || clients.Ims == nil {
+		t.Fatal("expected the STS, RAM and IMS clients to be built")
+	}
+
+	// Later calls return the clients built by the first one
+	sts, ram := clients.Sts, clients.Ram
+	if _, err := clients.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clients.Sts != sts || clients.Ram != ram {
+		t.Fatal("expected the clients to be built only once")
+	}
+}
+
+func TestAliCloudClientsBuildRequiresRegion(t *testing.T) {
+	clients := &AliCloudClients{
+		Config:                    &AliCloudClientConfig{AccessKey: "ak", AccessKeySecret: "secret"},
+		SkipCredentialsValidation: true,
+	}
+
+	if _, err := clients.Build(); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestAliCloudClientsBuildSharesCredential(t *testing.T) {
+	clients := &AliCloudClients{
+		Config:                    &AliCloudClientConfig{EcsRoleName: "terraform", Region: "cn-hangzhou"},
+		SkipCredentialsValidation: true,
+	}
+
+	if _, err := clients.Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clients.Sts.Credential == nil || clients.Ram.Credential != clients.Sts.Credential || clients.Ims.client.Credential != clients.Sts.Credential {
+		t.Fatal("expected the STS, RAM and IMS clients to share the credential")
+	}
+}
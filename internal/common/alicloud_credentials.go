@@ -0,0 +1,143 @@
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aliyun/credentials-go/credentials"
+	"github.com/aliyun/credentials-go/credentials/providers"
+)
+
+// AliCloudAssumeRoleConfig configures a RAM role assumed with the resolved base credentials.
+type AliCloudAssumeRoleConfig struct {
+	RoleArn           string // ARN of the RAM role to assume.
+	SessionName       string // Name of the role session, shown in the ActionTrail logs.
+	ExternalId        string // External ID required by the trust policy of the role, if any.
+	SessionExpiration int    // Lifetime of the session in seconds. The STS default is used if 0.
+}
+
+// AliCloudOidcConfig configures a RAM role assumed with an OIDC token, such as RRSA in ACK
+// or a GitHub Actions token.
+type AliCloudOidcConfig struct {
+	RoleArn         string // ARN of the RAM role to assume.
+	OidcProviderArn string // ARN of the OIDC identity provider trusted by the role.
+	OidcTokenFile   string // Path of the file holding the OIDC token.
+	SessionName     string // Name of the role session, shown in the ActionTrail logs.
+}
+
+// newAliCloudCredential resolves the credential of the AliCloud clients. The first configured
+// source wins, in this order: static access keys, ECS RAM role, OIDC, CLI profile, and finally
+// the default chain of credentials-go (ALIBABA_CLOUD_* environment variables, RRSA,
+// ~/.aliyun/config.json and the ECS instance metadata). The resolved credential is then used
+// to assume the configured RAM role, if any.
+func newAliCloudCredential(config *AliCloudClientConfig) (credentials.Credential, error) {
+	base, err := newAliCloudBaseCredentialsProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.AssumeRole == nil || config.AssumeRole.RoleArn == "" {
+		return credentials.FromCredentialsProvider(base.GetProviderName(), base), nil
+	}
+
+	builder := providers.NewRAMRoleARNCredentialsProviderBuilder().
+		WithCredentialsProvider(base).
+		WithRoleArn(config.AssumeRole.RoleArn).
+		WithRoleSessionName(config.AssumeRole.SessionName).
+		WithExternalId(config.AssumeRole.ExternalId).
+		WithStsRegionId(config.Region)
+	if config.AssumeRole.SessionExpiration > 0 {
+		builder = builder.WithDurationSeconds(config.AssumeRole.SessionExpiration)
+	}
+	provider, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure assume role %s: %v", config.AssumeRole.RoleArn, err)
+	}
+	return credentials.FromCredentialsProvider(provider.GetProviderName(), provider), nil
+}
+
+// newAliCloudBaseCredentialsProvider returns the provider of the credential used to call the
+// AliCloud APIs, or to assume the configured RAM role.
+func newAliCloudBaseCredentialsProvider(config *AliCloudClientConfig) (providers.CredentialsProvider, error) {
+	switch {
+	case config.AccessKey != "" || config.AccessKeySecret != "":
+		if config.AccessKey == "" || config.AccessKeySecret == "" {
+			return nil, fmt.Errorf("both the access key and the access key secret must be set")
+		}
+		if config.SecurityToken != "" {
+			return providers.NewStaticSTSCredentialsProviderBuilder().
+				WithAccessKeyId(config.AccessKey).
+				WithAccessKeySecret(config.AccessKeySecret).
+				WithSecurityToken(config.SecurityToken).
+				Build()
+		}
+		return providers.NewStaticAKCredentialsProviderBuilder().
+			WithAccessKeyId(config.AccessKey).
+			WithAccessKeySecret(config.AccessKeySecret).
+			Build()
+
+	case config.EcsRoleName != "":
+		return providers.NewECSRAMRoleCredentialsProviderBuilder().
+			WithRoleName(config.EcsRoleName).
+			Build()
+
+	case config.Oidc != nil:
+		return providers.NewOIDCCredentialsProviderBuilder().
+			WithRoleArn(config.Oidc.RoleArn).
+			WithOIDCProviderARN(config.Oidc.OidcProviderArn).
+			WithOIDCTokenFilePath(config.Oidc.OidcTokenFile).
+			WithRoleSessionName(config.Oidc.SessionName).
+			WithStsRegionId(config.Region).
+			Build()
+
+	case config.Profile != "" || config.ProfileFile != "":
+		return providers.NewCLIProfileCredentialsProviderBuilder().
+			WithProfileName(config.Profile).
+			WithProfileFile(config.ProfileFile).
+			Build()
+
+	default:
+		return providers.NewDefaultCredentialsProvider(), nil
+	}
+}
+
+// NewAliCloudClientConfigFromEnv returns the configuration of the AliCloud clients read from
+// the ALICLOUD_* environment variables.
+func NewAliCloudClientConfigFromEnv() *AliCloudClientConfig {
+	config := &AliCloudClientConfig{}
+	config.SetDefaultsFromEnv()
+	return config
+}
+
+// SetDefaultsFromEnv fills the fields left unset with the ALICLOUD_* environment variables.
+// The credential fields are only read from the environment when the configuration selects no
+// credential source, so that the environment never overrides a source chosen explicitly, such
+// as an ECS RAM role or a CLI profile.
+func (c *AliCloudClientConfig) SetDefaultsFromEnv() {
+	if !c.hasCredentialSource() {
+		c.AccessKey = os.Getenv("ALICLOUD_ACCESS_KEY")
+		c.AccessKeySecret = os.Getenv("ALICLOUD_ACCESS_SECRET")
+		c.SecurityToken = os.Getenv("ALICLOUD_SECURITY_TOKEN")
+		c.Profile = os.Getenv("ALICLOUD_PROFILE")
+		c.ProfileFile = os.Getenv("ALICLOUD_SHARED_CREDENTIALS_FILE")
+		c.EcsRoleName = os.Getenv("ALICLOUD_ECS_ROLE_NAME")
+	}
+	setDefaultFromEnv(&c.Region, "ALICLOUD_REGION")
+	setDefaultFromEnv(&c.StsEndpoint, "ALICLOUD_STS_ENDPOINT")
+	setDefaultFromEnv(&c.RamEndpoint, "ALICLOUD_RAM_ENDPOINT")
+	setDefaultFromEnv(&c.ImsEndpoint, "ALICLOUD_IMS_ENDPOINT")
+}
+
+// hasCredentialSource reports whether the configuration selects the source of the credential,
+// rather than leaving it to the default chain of credentials-go.
+func (c *AliCloudClientConfig) hasCredentialSource() bool {
+	return c.AccessKey != "" || c.AccessKeySecret != "" || c.SecurityToken != "" ||
+		c.EcsRoleName != "" || c.Oidc != nil || c.Profile != "" || c.ProfileFile != ""
+}
+
+// setDefaultFromEnv sets target to the value of the environment variable, if it is unset.
+func setDefaultFromEnv(target *string, key string) {
+	if *target == "" {
+		*target = os.Getenv(key)
+	}
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+func TestNewAliCloudCredential(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *AliCloudClientConfig
+		wantType string
+		wantErr  bool
+	}{
+		{
+			name:     "static access key",
+			config:   &AliCloudClientConfig{AccessKey: "ak", AccessKeySecret: "secret", Region: "cn-hangzhou"},
+			wantType: "static_ak",
+		},
+		{
+			name:     "sts token",
+			config:   &AliCloudClientConfig{AccessKey: "ak", AccessKeySecret: "secret", SecurityToken: "token", Region: "cn-hangzhou"},
+			wantType: "static_sts",
+		},
+		{
+			name:    "access key without secret",
+			config:  &AliCloudClientConfig{AccessKey: "ak", Region: "cn-hangzhou"},
+			wantErr: true,
+		},
+		{
+			name:     "ecs ram role",
+			config:   &AliCloudClientConfig{EcsRoleName: "terraform", Region: "cn-hangzhou"},
+			wantType: "ecs_ram_role",
+		},
+		{
+			name: "oidc",
+			config: &AliCloudClientConfig{Region: "cn-hangzhou", Oidc: &AliCloudOidcConfig{
+				RoleArn:         "acs:ram::1234567890:role/terraform",
+				OidcProviderArn: "acs:ram::1234567890:oidc-provider/github",
+				OidcTokenFile:   "/var/run/secrets/token",
+			}},
+			wantType: "oidc_role_arn",
+		},
+		{
+			name:     "cli profile",
+			config:   &AliCloudClientConfig{Profile: "ci", Region: "cn-hangzhou"},
+			wantType: "cli_profile",
+		},
+		{
+			name:     "default chain",
+			config:   &AliCloudClientConfig{Region: "cn-hangzhou"},
+			wantType: "default",
+		},
+		{
+			name: "assume role",
+			config: &AliCloudClientConfig{AccessKey: "ak", AccessKeySecret: "secret", Region: "cn-hangzhou", AssumeRole: &AliCloudAssumeRoleConfig{
+				RoleArn:     "acs:ram::1234567890:role/terraform",
+				SessionName: "terraform",
+				ExternalId:  "abc",
+			}},
+			wantType: "ram_role_arn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential, err := newAliCloudCredential(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tea.StringValue(credential.GetType()); got != tt.wantType {
+				t.Fatalf("expected credential type %q, got %q", tt.wantType, got)
+			}
+		})
+	}
+}
+
+func TestAliCloudClientConfigSetDefaultsFromEnv(t *testing.T) {
+	t.Setenv("ALICLOUD_ACCESS_KEY", "env-ak")
+	t.Setenv("ALICLOUD_ACCESS_SECRET", "env-secret")
+	t.Setenv("ALICLOUD_SECURITY_TOKEN", "")
+	t.Setenv("ALICLOUD_PROFILE", "")
+	t.Setenv("ALICLOUD_SHARED_CREDENTIALS_FILE", "")
+	t.Setenv("ALICLOUD_ECS_ROLE_NAME", "")
+	t.Setenv("ALICLOUD_REGION", "cn-shanghai")
+	t.Setenv("ALICLOUD_STS_ENDPOINT", "")
+	t.Setenv("ALICLOUD_RAM_ENDPOINT", "")
+	t.Setenv("ALICLOUD_IMS_ENDPOINT", "")
+
+	tests := []struct {
+		name       string
+		config     *AliCloudClientConfig
+		wantType   string
+		wantRegion string
+	}{
+		{
+			name:       "environment access key",
+			config:     &AliCloudClientConfig{},
+			wantType:   "static_ak",
+			wantRegion: "cn-shanghai",
+		},
+		{
+			name:       "ecs ram role over environment access key",
+			config:     &AliCloudClientConfig{EcsRoleName: "terraform", Region: "cn-hangzhou"},
+			wantType:   "ecs_ram_role",
+			wantRegion: "cn-hangzhou",
+		},
+		{
+			name:       "profile over environment access key",
+			config:     &AliCloudClientConfig{Profile: "ci"},
+			wantType:   "cli_profile",
+			wantRegion: "cn-shanghai",
+		},
+		{
+			name: "oidc over environment access key",
+			config: &AliCloudClientConfig{Oidc: &AliCloudOidcConfig{
+				RoleArn:         "acs:ram::1234567890:role/terraform",
+				OidcProviderArn: "acs:ram::1234567890:oidc-provider/github",
+				OidcTokenFile:   "/var/run/secrets/token",
+			}},
+			wantType:   "oidc_role_arn",
+			wantRegion: "cn-shanghai",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.SetDefaultsFromEnv()
+			if tt.config.Region != tt.wantRegion {
+				t.Errorf("expected region %q, got %q", tt.wantRegion, tt.config.Region)
+			}
+
+			credential, err := newAliCloudCredential(tt.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := tea.StringValue(credential.GetType()); got != tt.wantType {
+				t.Fatalf("expected credential type %q, got %q", tt.wantType, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package common
+
+// AliCloudRegions are the IDs of the public regions of Alibaba Cloud.
+var AliCloudRegions = []string{
+	// Chinese mainland
+	"cn-qingdao",
+	"cn-beijing",
+	"cn-zhangjiakou",
+	"cn-huhehaote",
+	"cn-wulanchabu",
+	"cn-hangzhou",
+	"cn-shanghai",
+	"cn-nanjing",
+	"cn-fuzhou",
+	"cn-shenzhen",
+	"cn-heyuan",
+	"cn-guangzhou",
+	"cn-chengdu",
+	"cn-wuhan-lr",
+	// Asia Pacific
+	"cn-hongkong",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-southeast-1",
+	"ap-southeast-3",
+	"ap-southeast-5",
+	"ap-southeast-6",
+	"ap-southeast-7",
+	"ap-south-1",
+	// Europe and Americas
+	"us-east-1",
+	"us-west-1",
+	"na-south-1",
+	"eu-west-1",
+	"eu-central-1",
+	// Middle East
+	"me-east-1",
+	"me-central-1",
+}
@@ -7,14 +7,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type CamClient struct {
-	Config *CamClientConfig
-	Client *http.Client
+	Config  *CamClientConfig
+	Client  *http.Client
+	Profile EndpointProfile // The profile of the endpoint type of the configuration.
+
+	limiter *requestLimiter // Paces the requests of all the resources sharing the client.
 }
 
 type CamClientConfig struct {
@@ -23,52 +28,136 @@ type CamClientConfig struct {
 	Region       *string
 	ApiKey       *string
 	BusinessId   *string
+	RetryPolicy  *RetryPolicy     // The retry policy for transient failures. The default policy is used if nil.
+	Transport    *TransportConfig // The settings of the HTTP transport. The default settings are used if nil.
+	RateLimit    *RateLimit       // The rate limit of the requests. The default rate limit is used if nil.
 }
 
 type CreateConnectionRequest struct {
-	AccountId      *string `json:"accountId" validate:"max=16"`
-	Region         *string `json:"region" validate:"max=254"`
-	RoleArn        *string `json:"roleArn" validate:"max=254"`
-	OidcProviderId *string `json:"oidcProviderId" validate:"max=254"`
-	Name           *string `json:"name" validate:"max=254"`
+	AccountId      *string `json:"accountId" validate:"required,digits,max=16"`
+	Region         *string `json:"region" validate:"required,max=254"`
+	RoleArn        *string `json:"roleArn" validate:"required,max=254"`
+	OidcProviderId *string `json:"oidcProviderId" validate:"required,max=254"`
+	Name           *string `json:"name" validate:"required,max=254"`
 	Description    *string `json:"description" validate:"omitempty,max=254"`
+
+	ConnectedSecurityServices *[]ConnectedSecurityService `json:"connectedSecurityServices,omitempty" validate:"omitempty,dive"` // The security services to enable on the account. The VisionOne defaults are used if nil, all disabled if empty.
 }
 
 type UpdateConnectionRequest struct {
-	Name        *string `json:"name"`        // The name of the Alibaba Cloud account to be used in Cloud Account Management.
-	Description *string `json:"description"` // The description of the Alibaba Cloud account. The default value is an empty string if the field is omitted.
+	Name        *string `json:"name" validate:"omitempty,max=254"`        // The name of the Alibaba Cloud account to be used in Cloud Account Management.
+	Description *string `json:"description" validate:"omitempty,max=254"` // The description of the Alibaba Cloud account. The default value is an empty string if the field is omitted.
+
+	ConnectedSecurityServices *[]ConnectedSecurityService `json:"connectedSecurityServices,omitempty" validate:"omitempty,dive"` // The security services enabled on the account. Left unchanged if nil, all disabled if empty.
+}
+
+// ConnectedSecurityService is a VisionOne security service enabled on an Alibaba Cloud account,
+// such as cloud posture or agentless vulnerability and threat detection.
+type ConnectedSecurityService struct {
+	Name        *string  `json:"name" validate:"required,max=254"` // The name of the security service.
+	InstanceIds []string `json:"instanceIds"`                      // The IDs of the instances of the service protecting the account.
+}
+
+type ListConnectionsRequest struct {
+	State *string // Only list the accounts in this state. All states are listed if nil.
+	Name  *string // Only list the accounts with this name. All names are listed if nil.
+}
+
+type ListConnectionsResponse struct {
+	Items     []*ReadConnectionResponse `json:"items"`     // The Alibaba Cloud accounts of the current page.
+	NextLink  *string                   `json:"nextLink"`  // The URL of the next page, nil on the last page.
+	SkipToken *string                   `json:"skipToken"` // The token of the next page, used when nextLink is not returned.
 }
 
 type ReadConnectionResponse struct {
-	Id                 *string `json:"id"`                 // The ID of the Alibaba Cloud account.
-	ParentStackRegion  *string `json:"parentStackRegion"`  // The region of Terraform backend where the state files are stored.
-	RoleArn            *string `json:"roleArn"`            // The Alibaba Cloud resource name (ARN) of the user role for Trend Vision One.
-	OidcProviderId     *string `json:"oidcProviderId"`     // The ID of the Alibaba Cloud OpenID Connect (OIDC) provider.
-	Name               *string `json:"name"`               // The name of the Alibaba Cloud account used in Cloud Account Management.
-	Description        *string `json:"description"`        // The description of the Alibaba Cloud account.
-	CreatedDateTime    *string `json:"createdDateTime"`    // The timestamp indicating when the Alibaba Cloud account was added to Trend Vision One.
-	UpdatedDateTime    *string `json:"updatedDateTime"`    // The timestamp indicating the last time the Alibaba Cloud account was modified.
-	State              *string `json:"state"`              // The status of the Alibaba Cloud account.
-	LastSyncedDateTime *string `json:"lastSyncedDateTime"` // The timestamp indicating the most recent synchronization of the Alibaba Cloud account with the cloud provider.
+	Id                 *string    `json:"id"`                 // The ID of the Alibaba Cloud account.
+	ParentStackRegion  *string    `json:"parentStackRegion"`  // The region of Terraform backend where the state files are stored.
+	RoleArn            *string    `json:"roleArn"`            // The Alibaba Cloud resource name (ARN) of the user role for Trend Vision One.
+	OidcProviderId     *string    `json:"oidcProviderId"`     // The ID of the Alibaba Cloud OpenID Connect (OIDC) provider.
+	Name               *string    `json:"name"`               // The name of the Alibaba Cloud account used in Cloud Account Management.
+	Description        *string    `json:"description"`        // The description of the Alibaba Cloud account.
+	CreatedDateTime    *time.Time `json:"createdDateTime"`    // The timestamp indicating when the Alibaba Cloud account was added to Trend Vision One.
+	UpdatedDateTime    *time.Time `json:"updatedDateTime"`    // The timestamp indicating the last time the Alibaba Cloud account was modified.
+	State              *string    `json:"state"`              // The status of the Alibaba Cloud account.
+	LastSyncedDateTime *time.Time `json:"lastSyncedDateTime"` // The timestamp indicating the most recent synchronization of the Alibaba Cloud account with the cloud provider.
+
+	ConnectedSecurityServices []ConnectedSecurityService `json:"connectedSecurityServices"` // The security services enabled on the Alibaba Cloud account.
+}
+
+// The states of a connected Alibaba Cloud account reported by Cloud Account Management.
+const (
+	ConnectionStateManaged  = "managed"  // The account is connected and Trend Vision One can access it.
+	ConnectionStateOutdated = "outdated" // The account is connected, but its stack needs to be updated.
+	ConnectionStateFailed   = "failed"   // Trend Vision One failed to access the account with the provided role.
+)
+
+// IsConnectionStateHealthy reports whether the state is a terminal state of a working connection.
+func IsConnectionStateHealthy(state string) bool {
+	return state == ConnectionStateManaged || state == ConnectionStateOutdated
+}
+
+// IsConnectionStateFailed reports whether the state is a terminal state of a broken connection.
+func IsConnectionStateFailed(state string) bool {
+	return state == ConnectionStateFailed
+}
+
+// DefaultSyncStaleThreshold is how long a connected account can go without syncing with
+// Alibaba Cloud before it is reported as stale.
+const DefaultSyncStaleThreshold = 24 * time.Hour
+
+// IsSyncStale reports whether the account did not sync with Alibaba Cloud within the threshold
+// before now. An account that never synced is stale once it was created longer than the
+// threshold ago, and an account without any timestamp is not reported as stale.
+func (r *ReadConnectionResponse) IsSyncStale(threshold time.Duration, now time.Time) bool {
+	syncedAt := r.LastSyncedDateTime
+	if syncedAt == nil {
+		syncedAt = r.CreatedDateTime
+	}
+	if syncedAt == nil {
+		return false
+	}
+	return now.Sub(*syncedAt) > threshold
 }
 
-var apiPathMap = map[string]map[string]string{
-	"create": {
-		"automation": "/v3.0/cam/alibabaAccounts",          // The endpoint would be like: https://api-int.visionone.trendmicro.com
-		"express":    "/public/cam/api/ui/alibabaAccounts", // The endpoint would be like: https://cloudaccounts-dev-us.visionone.trendmicro.com
-	},
-	"update": {
-		"automation": "/v3.0/cam/alibabaAccounts/%s",
-		"express":    "/public/cam/api/ui/alibabaAccounts/%s",
-	},
-	"delete": {
-		"automation": "/v3.0/cam/alibabaAccounts/%s",
-		"express":    "/public/cam/api/ui/alibabaAccounts/%s",
-	},
-	"read": {
-		"automation": "/v3.0/cam/alibabaAccounts/%s",
-		"express":    "/public/cam/api/ui/alibabaAccounts/%s",
-	},
+// UnmarshalJSON decodes the response, parsing its timestamps as RFC3339 and normalizing them to
+// UTC. Missing and empty timestamps are left nil, and a malformed timestamp is reported with the
+// name of its field.
+func (r *ReadConnectionResponse) UnmarshalJSON(data []byte) error {
+	type plainResponse ReadConnectionResponse
+	raw := struct {
+		*plainResponse
+		CreatedDateTime    *string `json:"createdDateTime"`
+		UpdatedDateTime    *string `json:"updatedDateTime"`
+		LastSyncedDateTime *string `json:"lastSyncedDateTime"`
+	}{plainResponse: (*plainResponse)(r)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var err error
+	if r.CreatedDateTime, err = parseCamTimestamp("createdDateTime", raw.CreatedDateTime); err != nil {
+		return err
+	}
+	if r.UpdatedDateTime, err = parseCamTimestamp("updatedDateTime", raw.UpdatedDateTime); err != nil {
+		return err
+	}
+	if r.LastSyncedDateTime, err = parseCamTimestamp("lastSyncedDateTime", raw.LastSyncedDateTime); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseCamTimestamp parses an RFC3339 timestamp of the CAM API, nil if it is missing or empty.
+func parseCamTimestamp(field string, value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected an RFC3339 timestamp like 2025-01-02T15:04:05Z", field, *value)
+	}
+	timestamp = timestamp.UTC()
+	return &timestamp, nil
 }
 
 // NewCamClient creates a new CamClient instance.
@@ -88,48 +177,130 @@ func NewCamClient(config *CamClientConfig) (*CamClient, error) {
 	if config.Region == nil || *config.Region == "" {
 		return nil, fmt.Errorf("region cannot be nil or empty")
 	}
+	if config.EndpointType == nil || *config.EndpointType == "" {
+		return nil, fmt.Errorf("endpoint type cannot be nil or empty")
+	}
+	profile, err := GetEndpointProfile(*config.EndpointType)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create an HTTP client with the Authorization header
-	client := &http.Client{}
+	// Create an HTTP client logging the requests and their responses
+	client, err := NewHTTPClient(config.Transport)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create and return the CamClient instance
 	return &CamClient{
-		Config: config,
-		Client: client,
+		Config:  config,
+		Client:  client,
+		Profile: profile,
+		limiter: newRequestLimiter(config.RateLimit),
 	}, nil
 }
 
-// DoRequest performs an HTTP request to the VisionOne API.
+// DoRequest performs an HTTP request to the VisionOne API, retrying transient failures
+// according to the retry policy of the client.
 func (c *CamClient) DoRequest(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	return c.DoRequestWithHeader(ctx, method, url, body, nil)
+}
+
+// DoRequestWithHeader performs an HTTP request to the VisionOne API like DoRequest, adding the
+// given headers to the request.
+func (c *CamClient) DoRequestWithHeader(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Response, error) {
+	policy := c.Config.RetryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+
+	for retry := 0; ; retry++ {
+		resp, err := c.doRequestOnce(ctx, method, url, body, header)
+		if ctx.Err() != nil {
+			// Do not retry once the caller gave up
+			if err == nil {
+				return resp, nil
+			}
+			return nil, err
+		}
+
+		ok, retryAfter := policy.shouldRetry(method, resp, err)
+		if !ok || retry >= policy.MaxRetries {
+			return resp, err
+		}
+
+		wait := policy.backoff(retry+1, retryAfter)
+		fields := map[string]any{
+			"method":  method,
+			"url":     url,
+			"attempt": retry + 1,
+			"wait":    wait.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status_code"] = resp.StatusCode
+			// Drain the body so that the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		LogWarn(ctx, "Retrying VisionOne API request", fields)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doRequestOnce performs a single attempt of an HTTP request to the VisionOne API.
+func (c *CamClient) doRequestOnce(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Response, error) {
 	bodyReader := bytes.NewReader(body)
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *c.Config.ApiKey))
-	req.Header.Set("x-customer-id", *c.Config.BusinessId)
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	c.Profile.SetAuthHeaders(req, c.Config)
 	req.Header.Set("x-task-id", GenerateUUID())
 	req.Header.Set("x-trace-id", GenerateUUID())
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.Client.Do(req)
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
 }
 
 func (c *CamClient) CreateConnection(ctx context.Context, req *CreateConnectionRequest) error {
-	body, err := json.Marshal(req)
-	if err != nil {
+	if err := validateCamRequest("create connection", req); err != nil {
 		return err
 	}
 
-	urlPattern, err := BuildUrlPattern(c.Config, "create")
+	body, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
-	url := urlPattern
 
-	tflog.Debug(ctx, fmt.Sprintf("CreateConnection URL: %s", url))
-	tflog.Debug(ctx, fmt.Sprintf("CreateConnection Request: %s", string(body)))
+	url := c.collectionUrl()
+
+	LogDebug(ctx, fmt.Sprintf("CreateConnection URL: %s", url))
+	LogDebug(ctx, fmt.Sprintf("CreateConnection Request: %s", string(body)))
 
 	resp, err := c.DoRequest(ctx, "POST", url, body)
 	if err != nil {
@@ -138,37 +309,26 @@ func (c *CamClient) CreateConnection(ctx context.Context, req *CreateConnectionR
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		// Read the response body to get more details about the error
-		respBodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read response body: %v", err)
-		}
-
-		var respBody map[string]interface{}
-		if err := json.Unmarshal(respBodyBytes, &respBody); err != nil {
-			return fmt.Errorf("failed to unmarshal response body: %v", err)
-		} else {
-			return fmt.Errorf("failed to create connection: status code %d, response: %s", resp.StatusCode, string(respBodyBytes))
-		}
+		return newCamAPIError("create connection", resp)
 	}
 
 	return nil
 }
 
 func (c *CamClient) UpdateConnection(ctx context.Context, accountId *string, req *UpdateConnectionRequest) error {
-	body, err := json.Marshal(req)
-	if err != nil {
+	if err := validateCamRequest("update connection", req); err != nil {
 		return err
 	}
 
-	urlPattern, err := BuildUrlPattern(c.Config, "update")
+	body, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf(urlPattern, *accountId)
 
-	tflog.Debug(ctx, fmt.Sprintf("UpdateConnection URL: %s", url))
-	tflog.Debug(ctx, fmt.Sprintf("UpdateConnection Request: %s", string(body)))
+	url := c.accountUrl(*accountId)
+
+	LogDebug(ctx, fmt.Sprintf("UpdateConnection URL: %s", url))
+	LogDebug(ctx, fmt.Sprintf("UpdateConnection Request: %s", string(body)))
 
 	resp, err := c.DoRequest(ctx, http.MethodPatch, url, body)
 	if err != nil {
@@ -176,17 +336,7 @@ func (c *CamClient) UpdateConnection(ctx context.Context, accountId *string, req
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		// Read the response body to get more details about the error
-		respBodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read response body: %v", err)
-		}
-		var respBody map[string]interface{}
-		if err := json.Unmarshal(respBodyBytes, &respBody); err != nil {
-			return fmt.Errorf("failed to unmarshal response body: %v", err)
-		} else {
-			return fmt.Errorf("failed to update connection: status code %d, response: %s", resp.StatusCode, string(respBodyBytes))
-		}
+		return newCamAPIError("update connection", resp)
 	}
 	return nil
 }
@@ -196,31 +346,24 @@ func (c *CamClient) DeleteConnection(ctx context.Context, accountId *string) err
 		return fmt.Errorf("account id cannot be empty")
 	}
 
-	urlPattern, err := BuildUrlPattern(c.Config, "delete")
-	if err != nil {
-		return err
-	}
-	url := fmt.Sprintf(urlPattern, *accountId)
+	url := c.accountUrl(*accountId)
 
-	tflog.Debug(ctx, fmt.Sprintf("DeleteConnection URL: %s", url))
-	tflog.Debug(ctx, fmt.Sprintf("DeleteConnection Account ID: %s", *accountId))
+	LogDebug(ctx, fmt.Sprintf("DeleteConnection URL: %s", url))
+	LogDebug(ctx, fmt.Sprintf("DeleteConnection Account ID: %s", *accountId))
 
 	resp, err := c.DoRequest(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// The account is already disconnected, nothing left to delete
+		LogDebug(ctx, fmt.Sprintf("DeleteConnection: account %s not found", *accountId))
+		return nil
+	}
 	if resp.StatusCode >= 300 {
-		// Read the response body to get more details about the error
-		respBodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return fmt.Errorf("failed to read response body: %v", err)
-		}
-		var respBody map[string]interface{}
-		if err := json.Unmarshal(respBodyBytes, &respBody); err != nil {
-			return fmt.Errorf("failed to unmarshal response body: %v", err)
-		} else {
-			return fmt.Errorf("failed to delete connection: status code %d, response: %s", resp.StatusCode, string(respBodyBytes))
-		}
+		return newCamAPIError("delete connection", resp)
 	}
 	return nil
 }
@@ -230,14 +373,10 @@ func (c *CamClient) ReadConnection(ctx context.Context, accountId *string) (*Rea
 		return nil, fmt.Errorf("account id cannot be empty")
 	}
 
-	urlPattern, err := BuildUrlPattern(c.Config, "read")
-	if err != nil {
-		return nil, err
-	}
-	url := fmt.Sprintf(urlPattern, *accountId)
+	url := c.accountUrl(*accountId)
 
-	tflog.Debug(ctx, fmt.Sprintf("ReadConnection URL: %s", url))
-	tflog.Debug(ctx, fmt.Sprintf("ReadConnection Account ID: %s", *accountId))
+	LogDebug(ctx, fmt.Sprintf("ReadConnection URL: %s", url))
+	LogDebug(ctx, fmt.Sprintf("ReadConnection Account ID: %s", *accountId))
 
 	resp, err := c.DoRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -248,20 +387,10 @@ func (c *CamClient) ReadConnection(ctx context.Context, accountId *string) (*Rea
 	if resp.StatusCode >= 300 {
 		if resp.StatusCode == http.StatusNotFound {
 			// Handle the case where the account is not found, return empty response or nil
-			tflog.Debug(ctx, fmt.Sprintf("ReadConnection: account %s not found", *accountId))
+			LogDebug(ctx, fmt.Sprintf("ReadConnection: account %s not found", *accountId))
 			return nil, nil
 		} else {
-			// Read the response body to get more details about the error
-			respBodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read response body: %v", err)
-			}
-			var respBody map[string]interface{}
-			if err := json.Unmarshal(respBodyBytes, &respBody); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal response body: %v", err)
-			} else {
-				return nil, fmt.Errorf("failed to read connection: status code %d, response: %s", resp.StatusCode, string(respBodyBytes))
-			}
+			return nil, newCamAPIError("read connection", resp)
 		}
 	}
 
@@ -272,69 +401,165 @@ func (c *CamClient) ReadConnection(ctx context.Context, accountId *string) (*Rea
 
 	if response == nil {
 		return nil, fmt.Errorf("response is nil")
-	} else {
-		if response.Id == nil {
-			response.Id = new(string)
-			*response.Id = ""
-		}
-		if response.ParentStackRegion == nil {
-			response.ParentStackRegion = new(string)
-			*response.ParentStackRegion = ""
+	}
+	response.setDefaults()
+	return response, nil
+}
+
+// ListConnections lists the connected Alibaba Cloud accounts matching the request, following
+// the pagination of the API until the last page.
+func (c *CamClient) ListConnections(ctx context.Context, req *ListConnectionsRequest) ([]*ReadConnectionResponse, error) {
+	url := c.collectionUrl()
+
+	header := http.Header{}
+	if filter := buildListConnectionsFilter(req); filter != "" {
+		header.Set("TMV1-Filter", filter)
+	}
+
+	LogDebug(ctx, fmt.Sprintf("ListConnections URL: %s", url))
+	LogDebug(ctx, fmt.Sprintf("ListConnections Filter: %s", header.Get("TMV1-Filter")))
+
+	connections := []*ReadConnectionResponse{}
+	for url != "" {
+		page, err := c.listConnectionsPage(ctx, url, header)
+		if err != nil {
+			return nil, err
 		}
-		if response.RoleArn == nil {
-			response.RoleArn = new(string)
-			*response.RoleArn = ""
+		for _, item := range page.Items {
+			if item == nil {
+				continue
+			}
+			item.setDefaults()
+			connections = append(connections, item)
 		}
-		if response.OidcProviderId == nil {
-			response.OidcProviderId = new(string)
-			*response.OidcProviderId = ""
+
+		next, err := nextPageUrl(url, page)
+		if err != nil {
+			return nil, err
 		}
-		if response.Name == nil {
-			response.Name = new(string)
-			*response.Name = ""
+		url = next
+	}
+	return connections, nil
+}
+
+// listConnectionsPage reads a single page of connected Alibaba Cloud accounts.
+func (c *CamClient) listConnectionsPage(ctx context.Context, url string, header http.Header) (*ListConnectionsResponse, error) {
+	resp, err := c.DoRequestWithHeader(ctx, http.MethodGet, url, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, newCamAPIError("list connections", resp)
+	}
+
+	page := &ListConnectionsResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return page, nil
+}
+
+// buildListConnectionsFilter builds the TMV1-Filter expression of the request.
+func buildListConnectionsFilter(req *ListConnectionsRequest) string {
+	if req == nil {
+		return ""
+	}
+
+	var conditions []string
+	if req.State != nil && *req.State != "" {
+		conditions = append(conditions, fmt.Sprintf("state eq '%s'", escapeFilterValue(*req.State)))
+	}
+	if req.Name != nil && *req.Name != "" {
+		conditions = append(conditions, fmt.Sprintf("name eq '%s'", escapeFilterValue(*req.Name)))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// escapeFilterValue escapes the single quotes of a TMV1-Filter string value.
+func escapeFilterValue(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// nextPageUrl returns the URL of the page following the given one, or an empty string
+// if it is the last page.
+func nextPageUrl(current string, page *ListConnectionsResponse) (string, error) {
+	if page.NextLink != nil && *page.NextLink != "" {
+		base, err := neturl.Parse(current)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse page URL: %v", err)
 		}
-		if response.Description == nil {
-			response.Description = new(string)
-			*response.Description = ""
+		next, err := base.Parse(*page.NextLink)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse the next page link: %v", err)
 		}
-		if response.CreatedDateTime == nil {
-			response.CreatedDateTime = new(string)
-			*response.CreatedDateTime = ""
+		// The request of the next page carries the API key, it must stay on the endpoint
+		if next.Scheme != base.Scheme || next.Host != base.Host {
+			return "", fmt.Errorf("the next page link %s is not on the VisionOne endpoint %s://%s", *page.NextLink, base.Scheme, base.Host)
 		}
-		if response.UpdatedDateTime == nil {
-			response.UpdatedDateTime = new(string)
-			*response.UpdatedDateTime = ""
+		if next.String() == current {
+			return "", fmt.Errorf("the next page link is the same as the current page: %s", current)
 		}
-		if response.State == nil {
-			response.State = new(string)
-			*response.State = ""
+		return next.String(), nil
+	}
+	if page.SkipToken != nil && *page.SkipToken != "" {
+		next, err := neturl.Parse(current)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse page URL: %v", err)
 		}
-		if response.LastSyncedDateTime == nil {
-			response.LastSyncedDateTime = new(string)
-			*response.LastSyncedDateTime = ""
+		query := next.Query()
+		if query.Get("skipToken") == *page.SkipToken {
+			return "", fmt.Errorf("the next page token is the same as the current page: %s", *page.SkipToken)
 		}
+		query.Set("skipToken", *page.SkipToken)
+		next.RawQuery = query.Encode()
+		return next.String(), nil
 	}
-	return response, nil
+	return "", nil
 }
 
-func BuildUrlPattern(c *CamClientConfig, method string) (string, error) {
-	pattern := ""
-
-	if len(*c.EndpointType) == 0 {
-		return "", fmt.Errorf("endpoint type cannot be missing or empty")
+// setDefaults replaces the fields missing from the response with empty values. The missing
+// timestamps are left nil.
+func (r *ReadConnectionResponse) setDefaults() {
+	if r.Id == nil {
+		r.Id = new(string)
+		*r.Id = ""
 	}
-
-	if m, ok := apiPathMap[method]; ok {
-		if path, ok := m[*c.EndpointType]; ok && path != "" {
-			pattern = fmt.Sprintf("%s%s", *c.Endpoint, path)
-		} else {
-			return "", fmt.Errorf("path is missing or empty. method: %s, endpoint type: %s", method, *c.EndpointType)
-		}
-	} else {
-		return "", fmt.Errorf("unrecognized endpoint type")
+	if r.ParentStackRegion == nil {
+		r.ParentStackRegion = new(string)
+		*r.ParentStackRegion = ""
+	}
+	if r.RoleArn == nil {
+		r.RoleArn = new(string)
+		*r.RoleArn = ""
+	}
+	if r.OidcProviderId == nil {
+		r.OidcProviderId = new(string)
+		*r.OidcProviderId = ""
+	}
+	if r.Name == nil {
+		r.Name = new(string)
+		*r.Name = ""
+	}
+	if r.Description == nil {
+		r.Description = new(string)
+		*r.Description = ""
 	}
+	if r.State == nil {
+		r.State = new(string)
+		*r.State = ""
+	}
+}
+
+// collectionUrl returns the URL of the Alibaba Cloud accounts collection.
+func (c *CamClient) collectionUrl() string {
+	return strings.TrimSuffix(*c.Config.Endpoint, "/") + c.Profile.BasePath()
+}
 
-	return pattern, nil
+// accountUrl returns the URL of an Alibaba Cloud account.
+func (c *CamClient) accountUrl(accountId string) string {
+	return c.collectionUrl() + "/" + neturl.PathEscape(accountId)
 }
 
 // GenerateUUID generates a new UUID string.
@@ -0,0 +1,447 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestCamClient returns a CamClient that sends requests to the given test server.
+func newTestCamClient(t *testing.T, server *httptest.Server) *CamClient {
+	t.Helper()
+
+	endpoint := server.URL
+	endpointType := "automation"
+	region := "us"
+	apiKey := "test-api-key"
+	businessId := "test-business-id"
+	client, err := NewCamClient(&CamClientConfig{
+		Endpoint:     &endpoint,
+		EndpointType: &endpointType,
+		Region:       &region,
+		ApiKey:       &apiKey,
+		BusinessId:   &businessId,
+	})
+	if err != nil {
+		t.Fatalf("failed to create CAM client: %v", err)
+	}
+	return client
+}
+
+func TestReadConnectionNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v3.0/cam/alibabaAccounts/1234567890" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	accountId := "1234567890"
+	resp, err := newTestCamClient(t, server).ReadConnection(context.Background(), &accountId)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp != nil {
+		t.Fatalf("expected nil response, got %+v", resp)
+	}
+}
+
+func TestDeleteConnectionNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/v3.0/cam/alibabaAccounts/1234567890" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"NotFound","message":"account not found"}}`))
+	}))
+	defer server.Close()
+
+	accountId := "1234567890"
+	if err := newTestCamClient(t, server).DeleteConnection(context.Background(), &accountId); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteConnectionServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":{"code":"InternalServerError","message":"boom"}}`))
+	}))
+	defer server.Close()
+
+	accountId := "1234567890"
+	if err := newTestCamClient(t, server).DeleteConnection(context.Background(), &accountId); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestDoRequestRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1234567890"}`))
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Config.RetryPolicy = &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	accountId := "1234567890"
+	resp, err := client.ReadConnection(context.Background(), &accountId)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp == nil || *resp.Id != accountId {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestDoRequestGivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Config.RetryPolicy = &RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	accountId := "1234567890"
+	if err := client.DeleteConnection(context.Background(), &accountId); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestDoRequestDoesNotReplayNonIdempotentRequests(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Config.RetryPolicy = &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	resp, err := client.DoRequest(context.Background(), http.MethodPost, server.URL, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDoRequestDoesNotReplayNonIdempotentRequestsWithRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Config.RetryPolicy = &RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	resp, err := client.DoRequest(context.Background(), http.MethodPost, server.URL, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestDoRequestHonorsRetryAfter(t *testing.T) {
+	var attemptTimes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptTimes = append(attemptTimes, time.Now())
+		if len(attemptTimes) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Config.RetryPolicy = &RetryPolicy{MaxRetries: 1, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	resp, err := client.DoRequest(context.Background(), http.MethodPost, server.URL, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	if len(attemptTimes) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attemptTimes))
+	}
+	if wait := attemptTimes[1].Sub(attemptTimes[0]); wait < time.Second {
+		t.Fatalf("expected to wait at least 1s before retrying, waited %s", wait)
+	}
+}
+
+func TestCamAPIError(t *testing.T) {
+	var traceId string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceId = r.Header.Get("x-trace-id")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"AccountAlreadyExists","message":"The account is already connected."}}`))
+	}))
+	defer server.Close()
+
+	err := newTestCamClient(t, server).CreateConnection(context.Background(), newTestCreateConnectionRequest())
+
+	var apiErr *CamAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected a CamAPIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("expected status code %d, got %d", http.StatusConflict, apiErr.StatusCode)
+	}
+	if apiErr.Code != "AccountAlreadyExists" {
+		t.Errorf("expected code AccountAlreadyExists, got %q", apiErr.Code)
+	}
+	if apiErr.Message != "The account is already connected." {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+	if apiErr.TraceId == "" || apiErr.TraceId != traceId {
+		t.Errorf("expected trace id %q, got %q", traceId, apiErr.TraceId)
+	}
+	if !IsCamAPIErrorStatus(err, http.StatusConflict) {
+		t.Error("expected IsCamAPIErrorStatus to match the status code")
+	}
+}
+
+func TestCamAPIErrorNonJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`<html><body>Unauthorized</body></html>`))
+	}))
+	defer server.Close()
+
+	accountId := "1234567890"
+	_, err := newTestCamClient(t, server).ReadConnection(context.Background(), &accountId)
+
+	var apiErr *CamAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected a CamAPIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status code %d, got %d", http.StatusUnauthorized, apiErr.StatusCode)
+	}
+	if !strings.Contains(err.Error(), "status code 401") || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("expected the error to include the status code and body, got %q", err.Error())
+	}
+}
+
+func TestIsCamAPIErrorAlreadyExists(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "conflict",
+			err:      &CamAPIError{StatusCode: http.StatusConflict, Code: "Conflict"},
+			expected: true,
+		},
+		{
+			name:     "bad request saying already exists",
+			err:      fmt.Errorf("wrapped: %w", &CamAPIError{StatusCode: http.StatusBadRequest, Code: "BadRequest", Message: "The account already exists."}),
+			expected: true,
+		},
+		{
+			name:     "already exists code",
+			err:      &CamAPIError{StatusCode: http.StatusUnprocessableEntity, Code: "AccountAlreadyExists"},
+			expected: true,
+		},
+		{
+			name: "other bad request",
+			err:  &CamAPIError{StatusCode: http.StatusBadRequest, Code: "BadRequest", Message: "roleArn is invalid"},
+		},
+		{
+			name: "server error saying already exists",
+			err:  &CamAPIError{StatusCode: http.StatusInternalServerError, Message: "already exists"},
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("connection reset"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := IsCamAPIErrorAlreadyExists(tt.err); actual != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestListConnectionsFollowsPagination(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3.0/cam/alibabaAccounts" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if filter := r.Header.Get("TMV1-Filter"); filter != "state eq 'managed' and name eq 'o''brien'" {
+			t.Errorf("unexpected filter %q", filter)
+		}
+		switch r.URL.Query().Get("skipToken") {
+		case "":
+			_, _ = fmt.Fprintf(w, `{"items":[{"id":"1"}],"nextLink":"%s/v3.0/cam/alibabaAccounts?skipToken=page2"}`, server.URL)
+		case "page2":
+			_, _ = w.Write([]byte(`{"items":[{"id":"2"}],"skipToken":"page3"}`))
+		case "page3":
+			_, _ = w.Write([]byte(`{"items":[{"id":"3","state":"managed"}]}`))
+		default:
+			t.Errorf("unexpected skip token %q", r.URL.Query().Get("skipToken"))
+		}
+	}))
+	defer server.Close()
+
+	state := "managed"
+	name := "o'brien"
+	connections, err := newTestCamClient(t, server).ListConnections(context.Background(), &ListConnectionsRequest{State: &state, Name: &name})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(connections) != 3 {
+		t.Fatalf("expected 3 connections, got %d", len(connections))
+	}
+	for i, connection := range connections {
+		if *connection.Id != fmt.Sprint(i+1) {
+			t.Errorf("expected connection %d to have id %d, got %s", i, i+1, *connection.Id)
+		}
+		if connection.RoleArn == nil {
+			t.Errorf("expected connection %d to have defaults set", i)
+		}
+	}
+}
+
+func TestListConnectionsRejectsNextLinkToAnotherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to another host with authorization %q", r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"items":[{"id":"1"}],"nextLink":"%s/v3.0/cam/alibabaAccounts?skipToken=page2"}`, other.URL)
+	}))
+	defer server.Close()
+
+	_, err := newTestCamClient(t, server).ListConnections(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "is not on the VisionOne endpoint") {
+		t.Fatalf("expected a next page link error, got %v", err)
+	}
+}
+
+func TestReadConnectionResponseIsSyncStale(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		lastSynced string
+		created    string
+		expected   bool
+	}{
+		{
+			name:       "synced recently",
+			lastSynced: "2025-06-01T06:00:00Z",
+			created:    "2025-01-01T00:00:00Z",
+		},
+		{
+			name:       "synced long ago",
+			lastSynced: "2025-05-30T12:00:00Z",
+			created:    "2025-01-01T00:00:00Z",
+			expected:   true,
+		},
+		{
+			name:    "never synced since a recent creation",
+			created: "2025-06-01T11:00:00Z",
+		},
+		{
+			name:     "never synced since an old creation",
+			created:  "2025-01-01T00:00:00Z",
+			expected: true,
+		},
+		{
+			name: "no timestamp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var connection ReadConnectionResponse
+			body := fmt.Sprintf(`{"lastSyncedDateTime":%q,"createdDateTime":%q}`, tt.lastSynced, tt.created)
+			if err := json.Unmarshal([]byte(body), &connection); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stale := connection.IsSyncStale(DefaultSyncStaleThreshold, now); stale != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, stale)
+			}
+		})
+	}
+}
+
+func TestReadConnectionDecodesTimestamps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"1234567890123456","createdDateTime":"2025-01-02T15:04:05+08:00",` +
+			`"updatedDateTime":"2025-01-03T00:00:00.123Z","lastSyncedDateTime":null}`))
+	}))
+	defer server.Close()
+
+	connection, err := newTestCamClient(t, server).ReadConnection(context.Background(), stringPointer("1234567890123456"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := time.Date(2025, 1, 2, 7, 4, 5, 0, time.UTC); connection.CreatedDateTime == nil || *connection.CreatedDateTime != expected {
+		t.Errorf("expected createdDateTime %s, got %v", expected, connection.CreatedDateTime)
+	}
+	if expected := time.Date(2025, 1, 3, 0, 0, 0, 123000000, time.UTC); connection.UpdatedDateTime == nil || !connection.UpdatedDateTime.Equal(expected) {
+		t.Errorf("expected updatedDateTime %s, got %v", expected, connection.UpdatedDateTime)
+	}
+	if connection.LastSyncedDateTime != nil {
+		t.Errorf("expected no lastSyncedDateTime, got %v", connection.LastSyncedDateTime)
+	}
+	if *connection.Id != "1234567890123456" {
+		t.Errorf("expected the other fields to be decoded, got id %q", *connection.Id)
+	}
+}
+
+func TestReadConnectionReportsMalformedTimestamp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"1234567890123456","updatedDateTime":"yesterday"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestCamClient(t, server).ReadConnection(context.Background(), stringPointer("1234567890123456"))
+	if err == nil || !strings.Contains(err.Error(), `invalid updatedDateTime "yesterday", expected an RFC3339 timestamp`) {
+		t.Errorf("expected a malformed updatedDateTime error, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// The types of VisionOne endpoints serving the Cloud Account Management API.
+const (
+	EndpointTypeAutomation = "automation" // The public automation API, like https://api.xdr.trendmicro.com
+	EndpointTypeExpress    = "express"    // The Cloud Account Management service API, like https://cloudaccounts-us.visionone.trendmicro.com
+)
+
+// ErrNoDefaultEndpoint is returned by EndpointProfile.DefaultEndpoint when the endpoint type
+// has no endpoint derived from the region.
+var ErrNoDefaultEndpoint = errors.New("no default endpoint")
+
+// EndpointProfile describes how the Cloud Account Management API is served by a type of
+// VisionOne endpoint.
+type EndpointProfile interface {
+	// Type returns the endpoint type of the profile, as set in visionone_endpoint_type.
+	Type() string
+	// ApiVersion returns the version of the API, empty if the endpoint is not versioned.
+	ApiVersion() string
+	// BasePath returns the path of the Alibaba Cloud accounts collection.
+	BasePath() string
+	// SetAuthHeaders sets the headers authenticating the request.
+	SetAuthHeaders(req *http.Request, config *CamClientConfig)
+	// DefaultEndpoint returns the endpoint of the VisionOne region, used when no endpoint is
+	// configured.
+	DefaultEndpoint(region string) (string, error)
+}
+
+// visionOneApiDomains are the domains of the automation API of the VisionOne regions.
+var visionOneApiDomains = map[string]string{
+	"us":  "api.xdr.trendmicro.com",
+	"eu":  "api.eu.xdr.trendmicro.com",
+	"jp":  "api.xdr.trendmicro.co.jp",
+	"sg":  "api.sg.xdr.trendmicro.com",
+	"au":  "api.au.xdr.trendmicro.com",
+	"in":  "api.in.xdr.trendmicro.com",
+	"mea": "api.mea.xdr.trendmicro.com",
+}
+
+// VisionOneRegions returns the VisionOne regions with a known automation API domain, sorted
+// by name.
+func VisionOneRegions() []string {
+	regions := make([]string, 0, len(visionOneApiDomains))
+	for region := range visionOneApiDomains {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// endpointProfiles are the supported endpoint profiles, indexed by endpoint type.
+var endpointProfiles = map[string]EndpointProfile{
+	EndpointTypeAutomation: AutomationEndpointProfile{Version: "v3.0"},
+	EndpointTypeExpress:    ExpressEndpointProfile{},
+}
+
+// EndpointTypes returns the supported endpoint types, sorted by name.
+func EndpointTypes() []string {
+	types := make([]string, 0, len(endpointProfiles))
+	for endpointType := range endpointProfiles {
+		types = append(types, endpointType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// GetEndpointProfile returns the profile of the endpoint type.
+func GetEndpointProfile(endpointType string) (EndpointProfile, error) {
+	profile, ok := endpointProfiles[endpointType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported endpoint type %q, expected one of: %s", endpointType, strings.Join(EndpointTypes(), ", "))
+	}
+	return profile, nil
+}
+
+// AutomationEndpointProfile is the profile of the public automation API, versioned in the path.
+type AutomationEndpointProfile struct {
+	Version string // The version of the API, such as v3.0.
+}
+
+// Type implements EndpointProfile.
+func (p AutomationEndpointProfile) Type() string {
+	return EndpointTypeAutomation
+}
+
+// ApiVersion implements EndpointProfile.
+func (p AutomationEndpointProfile) ApiVersion() string {
+	return p.Version
+}
+
+// BasePath implements EndpointProfile.
+func (p AutomationEndpointProfile) BasePath() string {
+	return "/" + p.Version + "/cam/alibabaAccounts"
+}
+
+// SetAuthHeaders implements EndpointProfile.
+func (p AutomationEndpointProfile) SetAuthHeaders(req *http.Request, config *CamClientConfig) {
+	setBearerAuthHeaders(req, config)
+}
+
+// DefaultEndpoint implements EndpointProfile.
+func (p AutomationEndpointProfile) DefaultEndpoint(region string) (string, error) {
+	domain, ok := visionOneApiDomains[region]
+	if !ok {
+		return "", fmt.Errorf("unknown VisionOne region %q, expected one of: %s", region, strings.Join(VisionOneRegions(), ", "))
+	}
+	return "https://" + domain, nil
+}
+
+// ExpressEndpointProfile is the profile of the API of the Cloud Account Management service.
+type ExpressEndpointProfile struct{}
+
+// Type implements EndpointProfile.
+func (p ExpressEndpointProfile) Type() string {
+	return EndpointTypeExpress
+}
+
+// ApiVersion implements EndpointProfile.
+func (p ExpressEndpointProfile) ApiVersion() string {
+	return ""
+}
+
+// BasePath implements EndpointProfile.
+func (p ExpressEndpointProfile) BasePath() string {
+	return "/public/cam/api/ui/alibabaAccounts"
+}
+
+// SetAuthHeaders implements EndpointProfile.
+func (p ExpressEndpointProfile) SetAuthHeaders(req *http.Request, config *CamClientConfig) {
+	setBearerAuthHeaders(req, config)
+}
+
+// DefaultEndpoint implements EndpointProfile. The express endpoints are private to each stack,
+// so they are never derived from the region.
+func (p ExpressEndpointProfile) DefaultEndpoint(region string) (string, error) {
+	return "", fmt.Errorf("%w for the %s endpoint type", ErrNoDefaultEndpoint, EndpointTypeExpress)
+}
+
+// setBearerAuthHeaders authenticates the request with the API key of the business.
+func setBearerAuthHeaders(req *http.Request, config *CamClientConfig) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", *config.ApiKey))
+	req.Header.Set("x-customer-id", *config.BusinessId)
+}
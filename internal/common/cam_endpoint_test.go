@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewCamClientRejectsUnsupportedEndpointType(t *testing.T) {
+	endpoint := "https://api.xdr.trendmicro.com"
+	endpointType := "manual"
+	region := "us"
+	apiKey := "test-api-key"
+	businessId := "test-business-id"
+	_, err := NewCamClient(&CamClientConfig{
+		Endpoint:     &endpoint,
+		EndpointType: &endpointType,
+		Region:       &region,
+		ApiKey:       &apiKey,
+		BusinessId:   &businessId,
+	})
+	if err == nil || !strings.Contains(err.Error(), `unsupported endpoint type "manual", expected one of: automation, express`) {
+		t.Fatalf("expected an unsupported endpoint type error, got %v", err)
+	}
+}
+
+func TestEndpointProfilePaths(t *testing.T) {
+	for _, endpointType := range EndpointTypes() {
+		t.Run(endpointType, func(t *testing.T) {
+			var paths []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				if r.Header.Get("Authorization") != "Bearer test-api-key" || r.Header.Get("x-customer-id") != "test-business-id" {
+					t.Errorf("missing auth headers: %v", r.Header)
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			client := newTestCamClient(t, server)
+			client.Profile, _ = GetEndpointProfile(endpointType)
+
+			accountId := "1234567890"
+			if _, err := client.ReadConnection(context.Background(), &accountId); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := client.Profile.BasePath() + "/1234567890"
+			if len(paths) != 1 || paths[0] != expected {
+				t.Errorf("expected request to %s, got %v", expected, paths)
+			}
+		})
+	}
+}
+
+func TestAutomationEndpointProfileVersion(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := newTestCamClient(t, server)
+	client.Profile = AutomationEndpointProfile{Version: "v3.1"}
+
+	accountId := "1234567890"
+	if err := client.DeleteConnection(context.Background(), &accountId); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/v3.1/cam/alibabaAccounts/1234567890" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
+
+func TestEndpointProfileDefaultEndpoint(t *testing.T) {
+	tests := []struct {
+		endpointType string
+		region       string
+		expected     string
+		expectedErr  string
+	}{
+		{endpointType: EndpointTypeAutomation, region: "us", expected: "https://api.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "eu", expected: "https://api.eu.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "jp", expected: "https://api.xdr.trendmicro.co.jp"},
+		{endpointType: EndpointTypeAutomation, region: "sg", expected: "https://api.sg.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "au", expected: "https://api.au.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "in", expected: "https://api.in.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "mea", expected: "https://api.mea.xdr.trendmicro.com"},
+		{endpointType: EndpointTypeAutomation, region: "ca", expectedErr: `unknown VisionOne region "ca", expected one of: au, eu, in, jp, mea, sg, us`},
+		{endpointType: EndpointTypeExpress, region: "us", expectedErr: "no default endpoint for the express endpoint type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpointType+"/"+tt.region, func(t *testing.T) {
+			profile, err := GetEndpointProfile(tt.endpointType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			endpoint, err := profile.DefaultEndpoint(tt.region)
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint != tt.expected {
+				t.Errorf("expected endpoint %s, got %s", tt.expected, endpoint)
+			}
+		})
+	}
+}
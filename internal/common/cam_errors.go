@@ -0,0 +1,99 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyLength is the maximum length of a non-JSON error body kept in a CamAPIError.
+const maxErrorBodyLength = 1024
+
+// CamAPIError is returned by CamClient when the VisionOne API responds with an error status code.
+type CamAPIError struct {
+	Operation  string // The operation that failed, such as "create connection".
+	StatusCode int    // The HTTP status code of the response.
+	Code       string // The VisionOne error code, empty if the response has no error body.
+	Message    string // The VisionOne error message, or the raw response body if it is not JSON.
+	TraceId    string // The x-trace-id header sent with the request, to be shared with Trend Micro support.
+}
+
+// camErrorResponse maps the error body returned by the VisionOne API.
+type camErrorResponse struct {
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Error implements the error interface.
+func (e *CamAPIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed to %s: status code %d", e.Operation, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, ", code: %s", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ", message: %s", e.Message)
+	}
+	if e.TraceId != "" {
+		fmt.Fprintf(&b, ", trace id: %s", e.TraceId)
+	}
+	return b.String()
+}
+
+// newCamAPIError builds a CamAPIError from an error response, reading its body.
+func newCamAPIError(operation string, resp *http.Response) *CamAPIError {
+	apiErr := &CamAPIError{
+		Operation:  operation,
+		StatusCode: resp.StatusCode,
+	}
+	if resp.Request != nil {
+		apiErr.TraceId = resp.Request.Header.Get("x-trace-id")
+	}
+
+	respBodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.Message = fmt.Sprintf("failed to read response body: %v", err)
+		return apiErr
+	}
+
+	var respBody camErrorResponse
+	if err := json.Unmarshal(respBodyBytes, &respBody); err == nil && respBody.Error != nil {
+		apiErr.Code = respBody.Error.Code
+		apiErr.Message = respBody.Error.Message
+	} else {
+		// Keep the raw body of non-JSON errors, such as the HTML page of a gateway
+		message := strings.TrimSpace(string(respBodyBytes))
+		if len(message) > maxErrorBodyLength {
+			message = message[:maxErrorBodyLength] + "..."
+		}
+		apiErr.Message = message
+	}
+	return apiErr
+}
+
+// IsCamAPIErrorStatus reports whether err is a CamAPIError with the given status code.
+func IsCamAPIErrorStatus(err error, statusCode int) bool {
+	var apiErr *CamAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsCamAPIErrorAlreadyExists reports whether err is a CamAPIError returned because the account
+// to connect is already connected, either with a 409 status code or with another client error
+// saying that it already exists.
+func IsCamAPIErrorAlreadyExists(err error) bool {
+	var apiErr *CamAPIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusConflict {
+		return true
+	}
+	message := strings.ToLower(apiErr.Code + " " + apiErr.Message)
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		(strings.Contains(message, "already exist") || strings.Contains(message, "alreadyexist"))
+}
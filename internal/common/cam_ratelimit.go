@@ -0,0 +1,88 @@
+package common
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	DefaultRequestsPerSecond   = 10
+	DefaultMaxInFlightRequests = 10
+)
+
+// RateLimit controls how fast CamClient sends requests, so that applying many connected
+// accounts in parallel does not exceed the rate limit of VisionOne.
+type RateLimit struct {
+	RequestsPerSecond   float64 // The average number of requests sent per second. Zero disables the limit.
+	MaxInFlightRequests int     // The maximum number of requests waiting for their response. Zero disables the limit.
+}
+
+// DefaultRateLimit returns the rate limit used when none is configured.
+func DefaultRateLimit() *RateLimit {
+	return &RateLimit{
+		RequestsPerSecond:   DefaultRequestsPerSecond,
+		MaxInFlightRequests: DefaultMaxInFlightRequests,
+	}
+}
+
+// requestLimiter paces the requests of a CamClient with a token bucket, and bounds the number
+// of requests in flight with a semaphore. It is shared by all the requests of the client.
+type requestLimiter struct {
+	tokens   *rate.Limiter // nil if the rate is not limited
+	inFlight chan struct{} // nil if the requests in flight are not limited
+}
+
+// newRequestLimiter creates a new requestLimiter enforcing the rate limit. The default rate
+// limit is used if limit is nil.
+func newRequestLimiter(limit *RateLimit) *requestLimiter {
+	if limit == nil {
+		limit = DefaultRateLimit()
+	}
+
+	limiter := &requestLimiter{}
+	if limit.RequestsPerSecond > 0 {
+		// Allow a burst of one second of requests, so that an idle client does not wait
+		burst := int(math.Max(1, math.Ceil(limit.RequestsPerSecond)))
+		limiter.tokens = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+	}
+	if limit.MaxInFlightRequests > 0 {
+		limiter.inFlight = make(chan struct{}, limit.MaxInFlightRequests)
+	}
+	return limiter
+}
+
+// acquire waits until a request can be sent. The returned function must be called once the
+// response is no longer in flight.
+func (l *requestLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.tokens != nil {
+		if err := l.tokens.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.inFlight }) }, nil
+}
+
+// releasingBody releases the in-flight slot of a request when its response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
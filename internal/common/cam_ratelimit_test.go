@@ -0,0 +1,99 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRateLimitedCamClient returns a CamClient with the given rate limit that sends
+// requests to the given test server.
+func newTestRateLimitedCamClient(t *testing.T, server *httptest.Server, limit *RateLimit) *CamClient {
+	t.Helper()
+
+	client := newTestCamClient(t, server)
+	client.limiter = newRequestLimiter(limit)
+	return client
+}
+
+// readTestConnections reads count connections in parallel and fails the test on error.
+func readTestConnections(t *testing.T, client *CamClient, count int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			accountId := "1234567890"
+			if _, err := client.ReadConnection(context.Background(), &accountId); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCamClientLimitsRequestRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// The burst of 20 requests is sent at once, the next 10 at 20 requests per second
+	client := newTestRateLimitedCamClient(t, server, &RateLimit{RequestsPerSecond: 20})
+	start := time.Now()
+	readTestConnections(t, client, 30)
+
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected the requests to be paced, took %s", elapsed)
+	}
+}
+
+func TestCamClientLimitsRequestsInFlight(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestRateLimitedCamClient(t, server, &RateLimit{MaxInFlightRequests: 2})
+	readTestConnections(t, client, 10)
+
+	if observed := maxInFlight.Load(); observed != 2 {
+		t.Errorf("expected at most 2 requests in flight, observed %d", observed)
+	}
+}
+
+func TestCamClientRateLimitHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTestRateLimitedCamClient(t, server, &RateLimit{RequestsPerSecond: 0.1})
+	accountId := "1234567890"
+	if _, err := client.ReadConnection(context.Background(), &accountId); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The next token is 10 seconds away, past the deadline of the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ReadConnection(ctx, &accountId); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 1 * time.Second
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how CamClient retries requests that failed with a transient error.
+type RetryPolicy struct {
+	MaxRetries int           // The maximum number of retries after the first attempt. Zero disables retries.
+	MinBackoff time.Duration // The backoff before the first retry, doubled on every following retry.
+	MaxBackoff time.Duration // The upper bound of the backoff between two attempts.
+	Jitter     bool          // Whether to randomize the backoff to spread retries of parallel requests.
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		Jitter:     true,
+	}
+}
+
+// retryableStatusCodes are the status codes returned by VisionOne for transient failures.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotentMethods are the methods that can be replayed without side effects.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// shouldRetry reports whether a request should be attempted again, and how long the server
+// asked to wait before doing so.
+func (p *RetryPolicy) shouldRetry(method string, resp *http.Response, err error) (bool, time.Duration) {
+	if err != nil {
+		// The request may have reached the server, only replay it when that is harmless
+		return idempotentMethods[method] && isRetryableError(err), 0
+	}
+	if !retryableStatusCodes[resp.StatusCode] {
+		return false, 0
+	}
+
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if idempotentMethods[method] {
+		return true, retryAfter
+	}
+	// A 429 means the API rate limited the request without processing it, so it is safe to
+	// replay even a non-idempotent request. A 502, 503 or 504 may come from a gateway or a
+	// proxy after the API processed the request, even with a Retry-After header.
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true, retryAfter
+	}
+	return false, 0
+}
+
+// backoff returns how long to wait before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int, retryAfter time.Duration) time.Duration {
+	wait := p.MinBackoff
+	for i := 1; i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter && wait > 0 {
+		// Equal jitter: keep half of the backoff and randomize the other half
+		half := wait / 2
+		wait = half + rand.N(half+1)
+	}
+	// Never retry earlier than the server asked for
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	return wait
+}
+
+// isRetryableError reports whether err is a transient network error.
+func isRetryableError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date. It
+// returns zero when the header is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
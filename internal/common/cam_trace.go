@@ -0,0 +1,150 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultTraceMaxBodyBytes is the number of bytes of the request and response bodies written
+// to the logs by TracingTransport when MaxBodyBytes is not set.
+const DefaultTraceMaxBodyBytes = 4096
+
+// traceBodyNotReplayable is logged instead of the body of a request without GetBody.
+const traceBodyNotReplayable = "<not replayable>"
+
+// TracingTransport is an http.RoundTripper that logs every VisionOne API request and its
+// response at Debug level. The headers and bodies are redacted and the bodies truncated, so
+// the logs can be shared, along with the trace ID of the request, with Trend Micro support.
+// The requests are sent untouched when the debug logs are disabled.
+type TracingTransport struct {
+	Base         http.RoundTripper // The transport sending the requests. http.DefaultTransport is used if nil.
+	MaxBodyBytes int               // The number of bytes of the bodies to log. DefaultTraceMaxBodyBytes is used if zero.
+}
+
+// NewTracingTransport creates a new TracingTransport sending the requests with the given
+// transport.
+func NewTracingTransport(base http.RoundTripper) *TracingTransport {
+	return &TracingTransport{Base: base}
+}
+
+// RoundTrip sends the request with the base transport and logs the request and its response.
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Do not read the bodies for logs that are not written
+	if !LogDebugEnabled() {
+		return t.base().RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	fields := map[string]any{
+		"method":   req.Method,
+		"url":      req.URL.String(),
+		"trace_id": req.Header.Get("x-trace-id"),
+		"task_id":  req.Header.Get("x-task-id"),
+	}
+
+	LogDebug(ctx, "Sending VisionOne API request", fields, map[string]any{
+		"request_headers": req.Header,
+		"request_body":    t.requestBody(req),
+	})
+
+	start := time.Now()
+	resp, err := t.base().RoundTrip(req)
+	fields["duration"] = time.Since(start).String()
+	if err != nil {
+		LogDebug(ctx, "VisionOne API request failed", fields, map[string]any{"error": err})
+		return nil, err
+	}
+
+	responseBody, err := t.responseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	LogDebug(ctx, "Received VisionOne API response", fields, map[string]any{
+		"status_code":      resp.StatusCode,
+		"response_headers": resp.Header,
+		"response_body":    responseBody,
+	})
+	return resp, nil
+}
+
+func (t *TracingTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *TracingTransport) maxBodyBytes() int {
+	if t.MaxBodyBytes <= 0 {
+		return DefaultTraceMaxBodyBytes
+	}
+	return t.MaxBodyBytes
+}
+
+// requestBody returns the sanitized body of the request. The body is read from a copy returned
+// by GetBody, as a RoundTripper must not modify the request, so it is not logged when the
+// request cannot provide a copy.
+func (t *TracingTransport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return traceBodyNotReplayable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, int64(t.maxBodyBytes())+1))
+	if err != nil {
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	return t.sanitizeBody(data)
+}
+
+// responseBody returns the sanitized beginning of the body of the response, leaving the whole
+// body to the caller.
+func (t *TracingTransport) responseBody(resp *http.Response) (string, error) {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBodyBytes())+1))
+	if err != nil {
+		resp.Body.Close()
+		return "", err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return t.sanitizeBody(data), nil
+}
+
+// sanitizeBody redacts the secrets of a body and truncates it to MaxBodyBytes. A JSON body is
+// redacted field by field, any other body as free text.
+func (t *TracingTransport) sanitizeBody(data []byte) string {
+	truncated := len(data) > t.maxBodyBytes()
+	if truncated {
+		data = data[:t.maxBodyBytes()]
+	}
+
+	body := RedactLogMessage(string(data))
+	var decoded any
+	if !truncated && json.Unmarshal(data, &decoded) == nil {
+		if redacted, err := json.Marshal(redactLogValue("", decoded)); err == nil {
+			body = string(redacted)
+		}
+	}
+	if truncated {
+		body += fmt.Sprintf("... (truncated to %d bytes)", t.maxBodyBytes())
+	}
+	return body
+}
@@ -0,0 +1,207 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// readLogEntries decodes the JSON log entries written by a test root logger.
+func readLogEntries(t *testing.T, output *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		entry := map[string]any{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode log entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// findLogEntry returns the first log entry with the given message.
+func findLogEntry(t *testing.T, entries []map[string]any, msg string) map[string]any {
+	t.Helper()
+
+	for _, entry := range entries {
+		if entry["@message"] == msg {
+			return entry
+		}
+	}
+	t.Fatalf("expected a %q log entry, got %v", msg, entries)
+	return nil
+}
+
+// roundTripperFunc is an http.RoundTripper calling a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// setTestDebugLogs sets the level of the provider logs for the duration of the test.
+func setTestDebugLogs(t *testing.T, level string) {
+	t.Helper()
+
+	for _, name := range debugLogEnvVars {
+		t.Setenv(name, "")
+	}
+	t.Setenv("TF_LOG_PROVIDER", level)
+}
+
+func TestTracingTransportSkipsDisabledDebugLogs(t *testing.T) {
+	setTestDebugLogs(t, "INFO")
+	body := io.NopCloser(strings.NewReader(`{"items":[]}`))
+	transport := NewTracingTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	}))
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.xdr.trendmicro.com/v3.0/cam/alibabaAccounts", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Body != body {
+		t.Error("expected the response body to be left unread")
+	}
+	if output.Len() != 0 {
+		t.Errorf("expected no logs, got %s", output.String())
+	}
+}
+
+func TestTracingTransportLogsRequestAndResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":"BadRequest","message":"invalid role","apiKey":"secret-api-key"}}`))
+	}))
+	defer server.Close()
+
+	setTestDebugLogs(t, "DEBUG")
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	name := "test"
+	err := newTestCamClient(t, server).UpdateConnection(ctx, stringPointer("1234567890"), &UpdateConnectionRequest{Name: &name})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid role") {
+		t.Errorf("expected the response body to reach the caller, got %v", err)
+	}
+	if strings.Contains(output.String(), "test-api-key") || strings.Contains(output.String(), "secret-api-key") {
+		t.Errorf("expected the secrets to be redacted from the logs:\n%s", output.String())
+	}
+
+	entries := readLogEntries(t, &output)
+	request := findLogEntry(t, entries, "Sending VisionOne API request")
+	response := findLogEntry(t, entries, "Received VisionOne API response")
+
+	if body, _ := request["request_body"].(string); request["method"] != http.MethodPatch || !strings.Contains(body, `"name":"test"`) {
+		t.Errorf("unexpected request entry: %v", request)
+	}
+	headers := request["request_headers"].(map[string]any)
+	if headers["Authorization"] != redactedLogValue {
+		t.Errorf("expected the Authorization header to be redacted, got %v", headers["Authorization"])
+	}
+	traceId, _ := request["trace_id"].(string)
+	if traceId == "" || response["trace_id"] != traceId {
+		t.Errorf("expected the request and response entries to share a trace ID, got %v and %v", request["trace_id"], response["trace_id"])
+	}
+	if response["status_code"] != float64(http.StatusBadRequest) || response["duration"] == nil {
+		t.Errorf("unexpected response entry: %v", response)
+	}
+	if body, _ := response["response_body"].(string); !strings.Contains(body, "invalid role") || !strings.Contains(body, `"apiKey":"***"`) {
+		t.Errorf("unexpected response body: %v", response["response_body"])
+	}
+}
+
+func TestTracingTransportTruncatesBody(t *testing.T) {
+	largeBody := strings.Repeat("a", 64)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(largeBody))
+	}))
+	defer server.Close()
+
+	setTestDebugLogs(t, "DEBUG")
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	client := &http.Client{Transport: &TracingTransport{MaxBodyBytes: 16}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if body.String() != largeBody {
+		t.Errorf("expected the whole body to reach the caller, got %q", body.String())
+	}
+
+	response := findLogEntry(t, readLogEntries(t, &output), "Received VisionOne API response")
+	if response["response_body"] != strings.Repeat("a", 16)+"... (truncated to 16 bytes)" {
+		t.Errorf("unexpected response body: %v", response["response_body"])
+	}
+}
+
+func TestTracingTransportDoesNotReadNonReplayableBody(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	setTestDebugLogs(t, "DEBUG")
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	// A body wrapped in a reader unknown to net/http has no GetBody
+	body := io.NopCloser(strings.NewReader(`{"name":"test"}`))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, body)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if req.GetBody != nil {
+		t.Fatal("expected the request to have no GetBody")
+	}
+
+	resp, err := (&TracingTransport{}).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if req.Body != body {
+		t.Error("expected the body of the request to be left unchanged")
+	}
+	if received != `{"name":"test"}` {
+		t.Errorf("expected the whole body to be sent, got %q", received)
+	}
+	request := findLogEntry(t, readLogEntries(t, &output), "Sending VisionOne API request")
+	if request["request_body"] != traceBodyNotReplayable {
+		t.Errorf("unexpected request body: %v", request["request_body"])
+	}
+}
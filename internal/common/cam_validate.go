@@ -0,0 +1,85 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// digitsPattern matches a non-empty string of ASCII digits, such as the ID of an AliCloud
+// account. Unlike the numeric tag of the validator, it rejects signs and decimal points.
+var digitsPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// camValidator evaluates the validate tags of the CAM requests. The fields are reported with
+// their JSON names, as they are sent to the VisionOne API.
+var camValidator = newCamValidator()
+
+func newCamValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	_ = v.RegisterValidation("digits", func(fl validator.FieldLevel) bool {
+		return digitsPattern.MatchString(fl.Field().String())
+	})
+	return v
+}
+
+// CamValidationError is returned by CamClient when a request breaks the constraints of its
+// validate tags. The request is not sent to the VisionOne API.
+type CamValidationError struct {
+	Operation string   // The operation of the request, such as "create connection".
+	Problems  []string // The broken constraints, one per invalid field.
+}
+
+// Error implements the error interface.
+func (e *CamValidationError) Error() string {
+	return fmt.Sprintf("invalid %s request: %s", e.Operation, strings.Join(e.Problems, "; "))
+}
+
+// validateCamRequest checks the request against its validate tags.
+func validateCamRequest(operation string, req any) error {
+	err := camValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return fmt.Errorf("failed to validate %s request: %v", operation, err)
+	}
+
+	validationErr := &CamValidationError{Operation: operation}
+	for _, fieldErr := range fieldErrs {
+		validationErr.Problems = append(validationErr.Problems, describeFieldError(fieldErr))
+	}
+	return validationErr
+}
+
+// describeFieldError returns a readable description of a broken constraint.
+func describeFieldError(fieldErr validator.FieldError) string {
+	// The namespace locates the fields of nested objects, like connectedSecurityServices[0].name
+	field := fieldErr.Field()
+	if _, namespace, ok := strings.Cut(fieldErr.Namespace(), "."); ok {
+		field = namespace
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters long", field, fieldErr.Param())
+	case "digits":
+		return fmt.Sprintf("%s must only contain digits", field)
+	default:
+		return fmt.Sprintf("%s does not satisfy %s", field, strings.TrimSuffix(fieldErr.Tag()+"="+fieldErr.Param(), "="))
+	}
+}
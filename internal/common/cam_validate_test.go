@@ -0,0 +1,209 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestCreateConnectionRequest returns a valid request to connect an account.
+func newTestCreateConnectionRequest() *CreateConnectionRequest {
+	return &CreateConnectionRequest{
+		AccountId:      stringPointer("1234567890123456"),
+		Region:         stringPointer("us-east-1"),
+		RoleArn:        stringPointer("acs:ram::1234567890123456:role/visionone"),
+		OidcProviderId: stringPointer("trendmicro-visionone"),
+		Name:           stringPointer("test"),
+	}
+}
+
+func TestCreateConnectionValidatesRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(req *CreateConnectionRequest)
+		expected string
+	}{
+		{
+			name:     "account id too long",
+			modify:   func(req *CreateConnectionRequest) { req.AccountId = stringPointer("12345678901234567") },
+			expected: "accountId must be at most 16 characters long",
+		},
+		{
+			name:     "account id not numeric",
+			modify:   func(req *CreateConnectionRequest) { req.AccountId = stringPointer("account") },
+			expected: "accountId must only contain digits",
+		},
+		{
+			name:     "account id decimal",
+			modify:   func(req *CreateConnectionRequest) { req.AccountId = stringPointer("1.5") },
+			expected: "accountId must only contain digits",
+		},
+		{
+			name:     "account id negative",
+			modify:   func(req *CreateConnectionRequest) { req.AccountId = stringPointer("-1") },
+			expected: "accountId must only contain digits",
+		},
+		{
+			name:     "account id signed",
+			modify:   func(req *CreateConnectionRequest) { req.AccountId = stringPointer("+1") },
+			expected: "accountId must only contain digits",
+		},
+		{
+			name:     "missing role arn",
+			modify:   func(req *CreateConnectionRequest) { req.RoleArn = nil },
+			expected: "roleArn is required",
+		},
+		{
+			name:     "name too long",
+			modify:   func(req *CreateConnectionRequest) { req.Name = stringPointer(strings.Repeat("a", 255)) },
+			expected: "name must be at most 254 characters long",
+		},
+		{
+			name:     "description too long",
+			modify:   func(req *CreateConnectionRequest) { req.Description = stringPointer(strings.Repeat("a", 255)) },
+			expected: "description must be at most 254 characters long",
+		},
+		{
+			name: "security service without name",
+			modify: func(req *CreateConnectionRequest) {
+				req.ConnectedSecurityServices = &[]ConnectedSecurityService{{InstanceIds: []string{"instance"}}}
+			},
+			expected: "connectedSecurityServices[0].name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}))
+			defer server.Close()
+
+			req := newTestCreateConnectionRequest()
+			tt.modify(req)
+			err := newTestCamClient(t, server).CreateConnection(context.Background(), req)
+
+			var validationErr *CamValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error to contain %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestCreateConnectionAllowsEmptyDescription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	req := newTestCreateConnectionRequest()
+	req.Description = stringPointer("")
+	err := newTestCamClient(t, server).CreateConnection(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreateConnectionSecurityServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services *[]ConnectedSecurityService
+		expected string
+	}{
+		{
+			name:     "defaults",
+			expected: `"description":null}`,
+		},
+		{
+			name:     "disabled",
+			services: &[]ConnectedSecurityService{},
+			expected: `"description":null,"connectedSecurityServices":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				raw, _ := io.ReadAll(r.Body)
+				body = string(raw)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			err := newTestCamClient(t, server).CreateConnection(context.Background(), &CreateConnectionRequest{
+				AccountId:      stringPointer("1234567890123456"),
+				Region:         stringPointer("us-east-1"),
+				RoleArn:        stringPointer("acs:ram::1234567890123456:role/visionone"),
+				OidcProviderId: stringPointer("visionone-oidc"),
+				Name:           stringPointer("test"),
+
+				ConnectedSecurityServices: tt.services,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(body, tt.expected) {
+				t.Errorf("expected request body ending with %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func TestUpdateConnectionSecurityServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services *[]ConnectedSecurityService
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			expected: `{"name":"test","description":null}`,
+		},
+		{
+			name:     "disabled",
+			services: &[]ConnectedSecurityService{},
+			expected: `{"name":"test","description":null,"connectedSecurityServices":[]}`,
+		},
+		{
+			name:     "enabled",
+			services: &[]ConnectedSecurityService{{Name: stringPointer("cloud-posture"), InstanceIds: []string{"instance"}}},
+			expected: `{"name":"test","description":null,"connectedSecurityServices":[{"name":"cloud-posture","instanceIds":["instance"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				raw, _ := io.ReadAll(r.Body)
+				body = string(raw)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			err := newTestCamClient(t, server).UpdateConnection(context.Background(), stringPointer("1234567890123456"), &UpdateConnectionRequest{
+				Name:                      stringPointer("test"),
+				ConnectedSecurityServices: tt.services,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body != tt.expected {
+				t.Errorf("expected request body %s, got %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// DefaultRequestTimeout is the timeout of a request attempt when none is configured.
+const DefaultRequestTimeout = 60 * time.Second
+
+// TransportConfig configures the HTTP transport shared by the VisionOne and AliCloud clients.
+type TransportConfig struct {
+	Timeout            time.Duration // The timeout of a request attempt, reading the response included. DefaultRequestTimeout is used if zero.
+	HttpProxy          string        // The URL of the proxy of the requests. The HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used if empty.
+	CaBundle           string        // PEM encoded CA certificates trusted in addition to the system ones.
+	ClientCertificate  string        // PEM encoded client certificate presented to the servers requesting one.
+	ClientKey          string        // PEM encoded private key of the client certificate.
+	InsecureSkipVerify bool          // Whether to skip the verification of the server certificates, for development stacks only.
+}
+
+// timeout returns the timeout of a request attempt.
+func (c *TransportConfig) timeout() time.Duration {
+	if c == nil || c.Timeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return c.Timeout
+}
+
+// NewHTTPTransport creates a new HTTP transport with the proxy and TLS settings of the
+// configuration. The default settings are used if config is nil.
+func NewHTTPTransport(config *TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config == nil {
+		return transport, nil
+	}
+
+	if config.HttpProxy != "" {
+		proxy, err := neturl.Parse(config.HttpProxy)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid HTTP proxy URL %q, expected a URL such as http://proxy.example.com:3128", config.HttpProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if config.CaBundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(config.CaBundle)) {
+			return nil, fmt.Errorf("the CA bundle does not contain any PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ClientCertificate != "" || config.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(config.ClientCertificate), []byte(config.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// NewHTTPClient creates a new HTTP client sending the requests with the transport of the
+// configuration, and logging them with a TracingTransport.
+func NewHTTPClient(config *TransportConfig) (*http.Client, error) {
+	transport, err := NewHTTPTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: NewTracingTransport(transport),
+		Timeout:   config.timeout(),
+	}, nil
+}
+
+// applyToOpenApiConfig sets the settings of the configuration supported by the AliCloud
+// clients. The AliCloud clients only skip the verification of the server certificates per
+// request, so InsecureSkipVerify is not applied to them.
+func (c *TransportConfig) applyToOpenApiConfig(config *openapi.Config) {
+	timeout := int(c.timeout().Milliseconds())
+	config.ConnectTimeout = tea.Int(timeout)
+	config.ReadTimeout = tea.Int(timeout)
+	if c == nil {
+		return
+	}
+
+	if c.HttpProxy != "" {
+		config.HttpProxy = tea.String(c.HttpProxy)
+		config.HttpsProxy = tea.String(c.HttpProxy)
+	}
+	if c.CaBundle != "" {
+		config.Ca = tea.String(c.CaBundle)
+	}
+	if c.ClientCertificate != "" {
+		config.Cert = tea.String(c.ClientCertificate)
+		config.Key = tea.String(c.ClientKey)
+	}
+}
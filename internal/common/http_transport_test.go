@@ -0,0 +1,164 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+// newTestClientCertificate returns a self-signed PEM encoded client certificate and its key.
+func newTestClientCertificate(t *testing.T, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyData, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}))
+}
+
+// getStatus sends a GET request with a client of the configuration and returns the status.
+func getStatus(t *testing.T, config *TransportConfig, url string) (int, error) {
+	t.Helper()
+
+	client, err := NewHTTPClient(config)
+	if err != nil {
+		t.Fatalf("failed to create HTTP client: %v", err)
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestNewHTTPClientVerifiesServerCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caBundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	if _, err := getStatus(t, nil, server.URL); err == nil {
+		t.Error("expected the untrusted server certificate to be rejected")
+	}
+	if status, err := getStatus(t, &TransportConfig{CaBundle: caBundle}, server.URL); err != nil || status != http.StatusOK {
+		t.Errorf("expected the CA bundle to be trusted, got %d, %v", status, err)
+	}
+	if status, err := getStatus(t, &TransportConfig{InsecureSkipVerify: true}, server.URL); err != nil || status != http.StatusOK {
+		t.Errorf("expected the verification to be skipped, got %d, %v", status, err)
+	}
+}
+
+func TestNewHTTPClientPresentsClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "terraform" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certificate, key := newTestClientCertificate(t, "terraform")
+	config := &TransportConfig{InsecureSkipVerify: true, ClientCertificate: certificate, ClientKey: key}
+	if status, err := getStatus(t, config, server.URL); err != nil || status != http.StatusOK {
+		t.Errorf("expected the client certificate to be accepted, got %d, %v", status, err)
+	}
+}
+
+func TestNewHTTPClientUsesProxy(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+	}))
+	defer proxy.Close()
+
+	if status, err := getStatus(t, &TransportConfig{HttpProxy: proxy.URL}, "http://visionone.invalid/v3.0/cam"); err != nil || status != http.StatusOK {
+		t.Fatalf("expected the request to be proxied, got %d, %v", status, err)
+	}
+	if url := <-proxied; url != "http://visionone.invalid/v3.0/cam" {
+		t.Errorf("expected the proxy to receive the request URL, got %s", url)
+	}
+}
+
+func TestNewHTTPClientTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	if _, err := getStatus(t, &TransportConfig{Timeout: 50 * time.Millisecond}, server.URL); err == nil {
+		t.Error("expected the request to time out")
+	}
+}
+
+func TestNewHTTPTransportInvalidConfig(t *testing.T) {
+	certificate, _ := newTestClientCertificate(t, "terraform")
+
+	tests := map[string]*TransportConfig{
+		"proxy without scheme":     {HttpProxy: "proxy.example.com:3128"},
+		"CA bundle without PEM":    {CaBundle: "not a certificate"},
+		"client certificate alone": {ClientCertificate: certificate},
+		"client key without PEM":   {ClientCertificate: certificate, ClientKey: "not a key"},
+	}
+
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewHTTPTransport(config); err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestTransportConfigApplyToOpenApiConfig(t *testing.T) {
+	config := &openapi.Config{}
+	(*TransportConfig)(nil).applyToOpenApiConfig(config)
+	if tea.IntValue(config.ReadTimeout) != 60000 || tea.IntValue(config.ConnectTimeout) != 60000 || config.HttpsProxy != nil {
+		t.Errorf("expected the default timeout only, got %+v", config)
+	}
+
+	config = &openapi.Config{}
+	(&TransportConfig{
+		Timeout:           10 * time.Second,
+		HttpProxy:         "http://proxy.example.com:3128",
+		CaBundle:          "ca",
+		ClientCertificate: "cert",
+		ClientKey:         "key",
+	}).applyToOpenApiConfig(config)
+	if tea.IntValue(config.ReadTimeout) != 10000 || tea.StringValue(config.HttpsProxy) != "http://proxy.example.com:3128" ||
+		tea.StringValue(config.Ca) != "ca" || tea.StringValue(config.Cert) != "cert" || tea.StringValue(config.Key) != "key" {
+		t.Errorf("unexpected config: %+v", config)
+	}
+}
@@ -0,0 +1,202 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	"github.com/alibabacloud-go/tea/dara"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	// imsApiVersion is the version of the AliCloud Identity Management Service API.
+	imsApiVersion = "2019-08-15"
+	// imsDefaultEndpoint is the endpoint of the IMS API, unless it is overridden.
+	imsDefaultEndpoint = "ims.aliyuncs.com"
+)
+
+// ImsClient calls the AliCloud Identity Management Service (IMS) API, which manages the OIDC
+// identity providers of an account. There is no IMS SDK among the dependencies, so the API is
+// called through the generic OpenAPI client.
+type ImsClient struct {
+	client *openapi.Client
+}
+
+// OidcProvider is an OIDC identity provider of an AliCloud account.
+type OidcProvider struct {
+	Arn              string   `json:"Arn"`
+	OIDCProviderName string   `json:"OIDCProviderName"`
+	IssuerUrl        string   `json:"IssuerUrl"`
+	Fingerprints     []string `json:"-"`
+	ClientIds        []string `json:"-"`
+	Description      string   `json:"Description"`
+}
+
+// CreateOidcProviderRequest holds the parameters of CreateOIDCProvider.
+type CreateOidcProviderRequest struct {
+	OIDCProviderName string
+	IssuerUrl        string
+	Fingerprints     []string
+	ClientIds        []string
+	Description      string
+}
+
+// NewImsClient creates an IMS client from the OpenAPI configuration of the AliCloud clients.
+// The endpoint defaults to ims.aliyuncs.com and the protocol to HTTPS.
+func NewImsClient(config *openapi.Config) (*ImsClient, error) {
+	if config.Endpoint == nil {
+		config.Endpoint = tea.String(imsDefaultEndpoint)
+	}
+	if config.Protocol == nil {
+		config.Protocol = tea.String("HTTPS")
+	}
+	client, err := openapi.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &ImsClient{client: client}, nil
+}
+
+// CreateOidcProvider creates an OIDC identity provider.
+func (c *ImsClient) CreateOidcProvider(req *CreateOidcProviderRequest) (*OidcProvider, error) {
+	query := map[string]*string{
+		"OIDCProviderName": tea.String(req.OIDCProviderName),
+		"IssuerUrl":        tea.String(req.IssuerUrl),
+		"Fingerprints":     tea.String(strings.Join(req.Fingerprints, ",")),
+		"ClientIds":        tea.String(strings.Join(req.ClientIds, ",")),
+	}
+	if req.Description != "" {
+		query["Description"] = tea.String(req.Description)
+	}
+	return c.callOidcProviderApi("CreateOIDCProvider", query)
+}
+
+// GetOidcProvider returns the OIDC identity provider with the given name, or nil if it does
+// not exist.
+func (c *ImsClient) GetOidcProvider(name string) (*OidcProvider, error) {
+	provider, err := c.callOidcProviderApi("GetOIDCProvider", map[string]*string{
+		"OIDCProviderName": tea.String(name),
+	})
+	if IsAliCloudNotFoundError(err) {
+		return nil, nil
+	}
+	return provider, err
+}
+
+// UpdateOidcProviderClientIds replaces the client IDs of an OIDC identity provider.
+func (c *ImsClient) UpdateOidcProviderClientIds(name string, clientIds []string) (*OidcProvider, error) {
+	return c.callOidcProviderApi("UpdateOIDCProvider", map[string]*string{
+		"OIDCProviderName": tea.String(name),
+		"ClientIds":        tea.String(strings.Join(clientIds, ",")),
+	})
+}
+
+// AddOidcProviderFingerprint adds a certificate fingerprint to an OIDC identity provider.
+func (c *ImsClient) AddOidcProviderFingerprint(name, fingerprint string) error {
+	_, err := c.callApi("AddFingerprintToOIDCProvider", map[string]*string{
+		"OIDCProviderName": tea.String(name),
+		"Fingerprint":      tea.String(fingerprint),
+	})
+	return err
+}
+
+// RemoveOidcProviderFingerprint removes a certificate fingerprint from an OIDC identity provider.
+func (c *ImsClient) RemoveOidcProviderFingerprint(name, fingerprint string) error {
+	_, err := c.callApi("RemoveFingerprintFromOIDCProvider", map[string]*string{
+		"OIDCProviderName": tea.String(name),
+		"Fingerprint":      tea.String(fingerprint),
+	})
+	return err
+}
+
+// DeleteOidcProvider deletes an OIDC identity provider. Deleting a missing provider succeeds.
+func (c *ImsClient) DeleteOidcProvider(name string) error {
+	_, err := c.callApi("DeleteOIDCProvider", map[string]*string{
+		"OIDCProviderName": tea.String(name),
+	})
+	if IsAliCloudNotFoundError(err) {
+		return nil
+	}
+	return err
+}
+
+// callOidcProviderApi calls an IMS action returning an OIDCProvider object.
+func (c *ImsClient) callOidcProviderApi(action string, query map[string]*string) (*OidcProvider, error) {
+	body, err := c.callApi(action, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		OIDCProvider *struct {
+			OidcProvider
+			Fingerprints string `json:"Fingerprints"`
+			ClientIds    string `json:"ClientIds"`
+		} `json:"OIDCProvider"`
+	}
+	if err := tea.Convert(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %v", action, err)
+	}
+	if response.OIDCProvider == nil {
+		return nil, fmt.Errorf("failed to decode %s response: OIDCProvider is missing", action)
+	}
+
+	provider := response.OIDCProvider.OidcProvider
+	provider.Fingerprints = splitList(response.OIDCProvider.Fingerprints)
+	provider.ClientIds = splitList(response.OIDCProvider.ClientIds)
+	return &provider, nil
+}
+
+// callApi calls an IMS action and returns the body of the response.
+func (c *ImsClient) callApi(action string, query map[string]*string) (map[string]interface{}, error) {
+	params := &openapi.Params{
+		Action:      tea.String(action),
+		Version:     tea.String(imsApiVersion),
+		Pathname:    tea.String("/"),
+		Method:      tea.String("POST"),
+		AuthType:    tea.String("AK"),
+		Style:       tea.String("RPC"),
+		ReqBodyType: tea.String("formData"),
+		BodyType:    tea.String("json"),
+	}
+	resp, err := c.client.CallApi(params, &openapi.OpenApiRequest{Query: query}, &dara.RuntimeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	body, ok := resp["body"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to decode %s response: body is missing", action)
+	}
+	return body, nil
+}
+
+// splitList splits a comma separated list returned by IMS.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IsAliCloudNotFoundError reports whether err is an AliCloud API error for a missing entity,
+// such as EntityNotExist.Role or EntityNotExist.OIDCProvider.
+func IsAliCloudNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	code := ""
+	var codeErr interface{ GetCode() *string }
+	var sdkErr *tea.SDKError
+	if errors.As(err, &codeErr) {
+		code = tea.StringValue(codeErr.GetCode())
+	} else if errors.As(err, &sdkErr) {
+		code = tea.StringValue(sdkErr.Code)
+	}
+	return strings.HasPrefix(code, "EntityNotExist")
+}
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedLogValue replaces the secrets in the logs.
+const redactedLogValue = "***"
+
+// sensitiveLogKeyParts are the parts of the field names holding secrets, compared with the
+// lower case field names stripped of separators, so that apiKey, api_key and x-api-key match.
+var sensitiveLogKeyParts = []string{
+	"apikey",
+	"secret",
+	"password",
+	"token",
+	"authorization",
+	"credential",
+	"signature",
+}
+
+// sensitiveLogValuePatterns match the secrets found in free text, such as an Authorization
+// header copied in an error message. They do not match the secrets already redacted.
+var sensitiveLogValuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bBearer\s+[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(?i)\b(AccessKeySecret|SecurityToken|api[_-]?key)(["']?\s*[:=]\s*["']?)[^\s"',&}*][^\s"',&}]*`),
+}
+
+// debugLogEnvVars are the environment variables of Terraform setting the level of the provider
+// logs.
+var debugLogEnvVars = []string{"TF_LOG", "TF_LOG_PROVIDER", "TF_LOG_PROVIDER_ALICLOUDSECURITY"}
+
+// LogDebugEnabled reports whether the debug logs of the provider may be written, so that the
+// fields that are costly to build can be skipped otherwise.
+func LogDebugEnabled() bool {
+	for _, name := range debugLogEnvVars {
+		switch strings.ToUpper(os.Getenv(name)) {
+		case "TRACE", "DEBUG", "JSON":
+			return true
+		}
+	}
+	return false
+}
+
+// LogTrace logs a trace message with the secrets of the message and fields redacted.
+func LogTrace(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	tflog.Trace(redactedLogContext(ctx), RedactLogMessage(msg), RedactLogFields(additionalFields...))
+}
+
+// LogDebug logs a debug message with the secrets of the message and fields redacted.
+func LogDebug(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	tflog.Debug(redactedLogContext(ctx), RedactLogMessage(msg), RedactLogFields(additionalFields...))
+}
+
+// LogInfo logs an info message with the secrets of the message and fields redacted.
+func LogInfo(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	tflog.Info(redactedLogContext(ctx), RedactLogMessage(msg), RedactLogFields(additionalFields...))
+}
+
+// LogWarn logs a warning message with the secrets of the message and fields redacted.
+func LogWarn(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	tflog.Warn(redactedLogContext(ctx), RedactLogMessage(msg), RedactLogFields(additionalFields...))
+}
+
+// LogError logs an error message with the secrets of the message and fields redacted.
+func LogError(ctx context.Context, msg string, additionalFields ...map[string]any) {
+	tflog.Error(redactedLogContext(ctx), RedactLogMessage(msg), RedactLogFields(additionalFields...))
+}
+
+// SetLogField sets a field on the logs of the context, with its secrets redacted.
+func SetLogField(ctx context.Context, key string, value any) context.Context {
+	return tflog.SetField(ctx, key, redactLogValue(key, value))
+}
+
+// redactedLogContext masks the secrets of the fields set on the context with tflog.SetField.
+func redactedLogContext(ctx context.Context) context.Context {
+	return tflog.MaskAllFieldValuesRegexes(ctx, sensitiveLogValuePatterns...)
+}
+
+// RedactLogMessage masks the secrets found in a log message.
+func RedactLogMessage(msg string) string {
+	for _, pattern := range sensitiveLogValuePatterns {
+		msg = pattern.ReplaceAllStringFunc(msg, func(match string) string {
+			submatch := pattern.FindStringSubmatch(match)
+			if len(submatch) == 3 {
+				// Keep the name of the secret and its separator
+				return submatch[1] + submatch[2] + redactedLogValue
+			}
+			return redactedLogValue
+		})
+	}
+	return msg
+}
+
+// RedactLogFields merges the log fields and masks the values of the sensitive fields. Nested
+// values, such as client configurations or HTTP headers, are redacted recursively.
+func RedactLogFields(additionalFields ...map[string]any) map[string]any {
+	fields := map[string]any{}
+	for _, additional := range additionalFields {
+		for key, value := range additional {
+			fields[key] = redactLogValue(key, value)
+		}
+	}
+	return fields
+}
+
+// isSensitiveLogKey reports whether a field name is the name of a secret.
+func isSensitiveLogKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(key))
+	if strings.HasSuffix(normalized, "file") {
+		// The path of a file holding a secret is not a secret
+		return false
+	}
+	for _, part := range sensitiveLogKeyParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactLogValue returns the value of a log field with its secrets masked.
+func redactLogValue(key string, value any) any {
+	if isSensitiveLogKey(key) {
+		if value == nil || value == "" {
+			return value
+		}
+		return redactedLogValue
+	}
+
+	switch v := value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return RedactLogMessage(v)
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return RedactLogMessage(*v)
+	case error:
+		return RedactLogMessage(v.Error())
+	case http.Header:
+		redacted := map[string]any{}
+		for name, values := range v {
+			redacted[name] = redactLogValue(name, strings.Join(values, ", "))
+		}
+		return redacted
+	case map[string]any:
+		redacted := map[string]any{}
+		for name, nested := range v {
+			redacted[name] = redactLogValue(name, nested)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, nested := range v {
+			redacted[i] = redactLogValue(key, nested)
+		}
+		return redacted
+	}
+
+	// Redact the structured values through their JSON representation, which is how tflog
+	// would write them.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%T", value)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return redactLogValue(key, decoded)
+}
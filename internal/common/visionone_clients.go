@@ -2,23 +2,25 @@ package common
 
 import (
 	"context"
-
-	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 type VisionOneClients struct {
 	Cam *CamClient
+
+	RetryPolicy *RetryPolicy     // The retry policy of the CAM client. The default policy is used if nil.
+	Transport   *TransportConfig // The settings of the HTTP transport of the CAM client. The default settings are used if nil.
+	RateLimit   *RateLimit       // The rate limit of the CAM client. The default rate limit is used if nil.
 }
 
-func (v *VisionOneClients) Build(endpoint, endpointType, businessId, apiKey, region string) (*VisionOneClients, error) {
-	_, err := v.BuildCamClient(endpoint, endpointType, businessId, apiKey, region)
+func (v *VisionOneClients) Build(ctx context.Context, endpoint, endpointType, businessId, apiKey, region string) (*VisionOneClients, error) {
+	_, err := v.BuildCamClient(ctx, endpoint, endpointType, businessId, apiKey, region)
 	if err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
-func (v *VisionOneClients) BuildCamClient(endpoint, endpointType, businessId, apiKey, region string) (*CamClient, error) {
+func (v *VisionOneClients) BuildCamClient(ctx context.Context, endpoint, endpointType, businessId, apiKey, region string) (*CamClient, error) {
 	if v.Cam != nil {
 		return v.Cam, nil
 	}
@@ -29,16 +31,20 @@ func (v *VisionOneClients) BuildCamClient(endpoint, endpointType, businessId, ap
 		BusinessId:   &businessId,
 		ApiKey:       &apiKey,
 		Region:       &region,
+		RetryPolicy:  v.RetryPolicy,
+		Transport:    v.Transport,
+		RateLimit:    v.RateLimit,
 	}
 	client, err := NewCamClient(config)
 	if err != nil {
 		return nil, err
 	}
 
-	tflog.Info(context.Background(), "CAM client created successfully", map[string]any{
-		"businessId": businessId,
-		"apiKey":     apiKey,
-		"region":     region,
+	LogInfo(ctx, "CAM client created successfully", map[string]any{
+		"endpoint":     endpoint,
+		"endpointType": endpointType,
+		"businessId":   businessId,
+		"region":       region,
 	})
 
 	v.Cam = client
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// TrustPolicyDocument is the trust policy of a RAM role, stored as the AssumeRolePolicyDocument.
+type TrustPolicyDocument struct {
+	Statement []TrustPolicyStatement `json:"Statement"`
+	Version   string                 `json:"Version"`
+}
+
+// TrustPolicyStatement is a statement of a RAM role trust policy.
+type TrustPolicyStatement struct {
+	Action    string                    `json:"Action"`
+	Condition map[string]map[string]any `json:"Condition,omitempty"`
+	Effect    string                    `json:"Effect"`
+	Principal TrustPolicyPrincipal      `json:"Principal"`
+}
+
+// TrustPolicyPrincipal is the principal allowed to assume a RAM role.
+type TrustPolicyPrincipal struct {
+	Federated []string `json:"Federated,omitempty"`
+}
+
+// VisionOneOidcIssuerUrl returns the issuer of the OIDC tokens presented by the Cloud Account
+// Management service of the given VisionOne region.
+func VisionOneOidcIssuerUrl(region string) string {
+	return fmt.Sprintf("https://cloudaccounts-%s.visionone.trendmicro.com", region)
+}
+
+// BuildOidcProviderArn returns the ARN of an OIDC identity provider of an AliCloud account.
+func BuildOidcProviderArn(accountId, oidcProviderName string) string {
+	return fmt.Sprintf("acs:ram::%s:oidc-provider/%s", accountId, oidcProviderName)
+}
+
+// BuildVisionOneTrustPolicy returns the canonical trust policy of the RAM role assumed by
+// VisionOne. The role trusts the OIDC identity provider of the account, and only for the tokens
+// issued by the VisionOne region to the business.
+func BuildVisionOneTrustPolicy(accountId, oidcProviderId, businessId, region string) (string, error) {
+	if businessId == "" {
+		return "", fmt.Errorf("business ID cannot be empty")
+	}
+	if region == "" {
+		return "", fmt.Errorf("region cannot be empty")
+	}
+	return BuildOidcTrustPolicy(accountId, oidcProviderId, VisionOneOidcIssuerUrl(region), []string{businessId})
+}
+
+// BuildOidcTrustPolicy returns the trust policy of a RAM role assumed through the OIDC identity
+// provider of the account, only for the tokens of the issuer with one of the audiences. A single
+// audience is written as a string, as in the canonical VisionOne trust policy.
+func BuildOidcTrustPolicy(accountId, oidcProviderId, issuerUrl string, audiences []string) (string, error) {
+	for _, field := range []struct{ name, value string }{
+		{"account ID", accountId},
+		{"OIDC provider ID", oidcProviderId},
+		{"issuer URL", issuerUrl},
+	} {
+		if field.value == "" {
+			return "", fmt.Errorf("%s cannot be empty", field.name)
+		}
+	}
+	if len(audiences) == 0 || slices.Contains(audiences, "") {
+		return "", fmt.Errorf("audiences cannot be empty")
+	}
+
+	var audience any = audiences[0]
+	if len(audiences) > 1 {
+		audience = slices.Sorted(slices.Values(audiences))
+	}
+	document := TrustPolicyDocument{
+		Statement: []TrustPolicyStatement{
+			{
+				Action: "sts:AssumeRole",
+				Condition: map[string]map[string]any{
+					"StringEquals": {
+						"oidc:aud": audience,
+						"oidc:iss": issuerUrl,
+					},
+				},
+				Effect: "Allow",
+				Principal: TrustPolicyPrincipal{
+					Federated: []string{BuildOidcProviderArn(accountId, oidcProviderId)},
+				},
+			},
+		},
+		Version: "1",
+	}
+
+	policy, err := json.Marshal(document)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal trust policy: %v", err)
+	}
+	return string(policy), nil
+}
+
+// TrustPolicyAllowsFederatedPrincipal reports whether a trust policy allows the federated
+// principal, such as the ARN of an OIDC provider, to assume the role. The Action and Federated
+// elements may be written either as a string or as a list of strings.
+func TrustPolicyAllowsFederatedPrincipal(document, principal string) (bool, error) {
+	var policy struct {
+		Statement []struct {
+			Action    json.RawMessage `json:"Action"`
+			Effect    string          `json:"Effect"`
+			Principal struct {
+				Federated json.RawMessage `json:"Federated"`
+			} `json:"Principal"`
+		} `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return false, fmt.Errorf("failed to parse trust policy: %v", err)
+	}
+
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" {
+			continue
+		}
+		actions, err := unmarshalStringOrList(statement.Action)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse the Action of the trust policy: %v", err)
+		}
+		federated, err := unmarshalStringOrList(statement.Principal.Federated)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse the Federated principal of the trust policy: %v", err)
+		}
+		if slices.Contains(actions, "sts:AssumeRole") && slices.Contains(federated, principal) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// unmarshalStringOrList decodes a policy element holding a string or a list of strings.
+func unmarshalStringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []string{value}, nil
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
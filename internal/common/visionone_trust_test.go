@@ -0,0 +1,127 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// readTestTrustPolicy returns the compacted trust policy of the testdata fixture. The fixture is
+// written by hand in the form of the RAM OIDC trust policies documented by AliCloud, for the
+// business 2a1b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d of the us VisionOne region trusting the
+// trendmicro-visionone OIDC provider of the account 1234567890123456.
+func readTestTrustPolicy(t *testing.T) string {
+	t.Helper()
+
+	document, err := os.ReadFile("testdata/visionone_trust_policy.json")
+	if err != nil {
+		t.Fatalf("failed to read the trust policy fixture: %v", err)
+	}
+	var policy bytes.Buffer
+	if err := json.Compact(&policy, document); err != nil {
+		t.Fatalf("failed to compact the trust policy fixture: %v", err)
+	}
+	return policy.String()
+}
+
+func TestBuildVisionOneTrustPolicy(t *testing.T) {
+	policy, err := BuildVisionOneTrustPolicy("1234567890123456", "trendmicro-visionone", "2a1b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d", "us")
+	if err != nil {
+		t.Fatalf("BuildVisionOneTrustPolicy returned error: %v", err)
+	}
+
+	if expected := readTestTrustPolicy(t); policy != expected {
+		t.Errorf("unexpected trust policy:\n got: %s\nwant: %s", policy, expected)
+	}
+}
+
+func TestVisionOneOidcIssuerUrl(t *testing.T) {
+	// The tokens are issued by the Cloud Account Management service of the region, which also
+	// serves the express endpoint
+	tests := map[string]string{
+		"us": "https://cloudaccounts-us.visionone.trendmicro.com",
+		"eu": "https://cloudaccounts-eu.visionone.trendmicro.com",
+		"jp": "https://cloudaccounts-jp.visionone.trendmicro.com",
+	}
+	for region, expected := range tests {
+		if issuer := VisionOneOidcIssuerUrl(region); issuer != expected {
+			t.Errorf("unexpected issuer of the %s region: got %s, want %s", region, issuer, expected)
+		}
+	}
+}
+
+func TestBuildVisionOneTrustPolicyMissingInput(t *testing.T) {
+	_, err := BuildVisionOneTrustPolicy("1234567890123456", "trendmicro-visionone", "", "us")
+	if err == nil || err.Error() != "business ID cannot be empty" {
+		t.Errorf("expected business ID error, got %v", err)
+	}
+}
+
+func TestBuildOidcTrustPolicy(t *testing.T) {
+	policy, err := BuildOidcTrustPolicy("1234567890123456", "trendmicro-visionone", "https://issuer.example.com", []string{"client-b", "client-a"})
+	if err != nil {
+		t.Fatalf("BuildOidcTrustPolicy returned error: %v", err)
+	}
+
+	expected := `{"Statement":[{"Action":"sts:AssumeRole","Condition":{"StringEquals":{"oidc:aud":["client-a","client-b"],"oidc:iss":"https://issuer.example.com"}},"Effect":"Allow","Principal":{"Federated":["acs:ram::1234567890123456:oidc-provider/trendmicro-visionone"]}}],"Version":"1"}`
+	if policy != expected {
+		t.Errorf("unexpected trust policy:\n got: %s\nwant: %s", policy, expected)
+	}
+
+	if _, err := BuildOidcTrustPolicy("1234567890123456", "trendmicro-visionone", "https://issuer.example.com", nil); err == nil {
+		t.Error("expected an error without audiences")
+	}
+}
+
+func TestTrustPolicyAllowsFederatedPrincipal(t *testing.T) {
+	principal := "acs:ram::1234567890123456:oidc-provider/trendmicro-visionone"
+
+	tests := []struct {
+		name     string
+		document string
+		expected bool
+	}{
+		{
+			name:     "list elements",
+			document: `{"Statement":[{"Action":["sts:AssumeRole"],"Effect":"Allow","Principal":{"Federated":["` + principal + `"]}}],"Version":"1"}`,
+			expected: true,
+		},
+		{
+			name:     "string elements",
+			document: `{"Statement":[{"Action":"sts:AssumeRole","Effect":"Allow","Principal":{"Federated":"` + principal + `"}}],"Version":"1"}`,
+			expected: true,
+		},
+		{
+			name:     "other principal",
+			document: `{"Statement":[{"Action":"sts:AssumeRole","Effect":"Allow","Principal":{"Federated":"acs:ram::1234567890123456:oidc-provider/other"}}],"Version":"1"}`,
+			expected: false,
+		},
+		{
+			name:     "denied",
+			document: `{"Statement":[{"Action":"sts:AssumeRole","Effect":"Deny","Principal":{"Federated":"` + principal + `"}}],"Version":"1"}`,
+			expected: false,
+		},
+		{
+			name:     "service principal",
+			document: `{"Statement":[{"Action":"sts:AssumeRole","Effect":"Allow","Principal":{"Service":["ecs.aliyuncs.com"]}}],"Version":"1"}`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, err := TrustPolicyAllowsFederatedPrincipal(tt.document, principal)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if allowed != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, allowed)
+			}
+		})
+	}
+
+	if _, err := TrustPolicyAllowsFederatedPrincipal("not json", principal); err == nil {
+		t.Error("expected an error for an invalid document")
+	}
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = &parseArnFunction{}
+	_ function.Function = &buildRoleArnFunction{}
+	_ function.Function = &buildOidcProviderArnFunction{}
+)
+
+// arnAttributeTypes are the attributes of the object returned by parse_arn.
+var arnAttributeTypes = map[string]attr.Type{
+	"service":       types.StringType,
+	"region":        types.StringType,
+	"account_id":    types.StringType,
+	"resource_type": types.StringType,
+	"resource_name": types.StringType,
+}
+
+// arnModel maps the object returned by parse_arn.
+type arnModel struct {
+	Service      types.String `tfsdk:"service"`
+	Region       types.String `tfsdk:"region"`
+	AccountId    types.String `tfsdk:"account_id"`
+	ResourceType types.String `tfsdk:"resource_type"`
+	ResourceName types.String `tfsdk:"resource_name"`
+}
+
+// NewParseArnFunction is a helper function to simplify the provider implementation.
+func NewParseArnFunction() function.Function {
+	return &parseArnFunction{}
+}
+
+// parseArnFunction splits an Alibaba Cloud ARN into its parts.
+type parseArnFunction struct{}
+
+// Metadata returns the function name.
+func (f *parseArnFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_arn"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *parseArnFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse an Alibaba Cloud ARN",
+		Description: "Given an Alibaba Cloud ARN like acs:ram::<account>:role/<name>, return its service, region, account_id, " +
+			"resource_type and resource_name. The region is empty for global services such as RAM, and the resource_type " +
+			"is empty if the resource has no slash.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "arn",
+				Description: "The Alibaba Cloud ARN to parse.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: arnAttributeTypes,
+		},
+	}
+}
+
+// Run parses the ARN.
+func (f *parseArnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var arn string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &arn))
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := common.ParseArn(arn)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, arnModel{
+		Service:      types.StringValue(parsed.Service),
+		Region:       types.StringValue(parsed.Region),
+		AccountId:    types.StringValue(parsed.AccountId),
+		ResourceType: types.StringValue(parsed.ResourceType),
+		ResourceName: types.StringValue(parsed.ResourceName),
+	}))
+}
+
+// NewBuildRoleArnFunction is a helper function to simplify the provider implementation.
+func NewBuildRoleArnFunction() function.Function {
+	return &buildRoleArnFunction{}
+}
+
+// buildRoleArnFunction assembles the ARN of a RAM role.
+type buildRoleArnFunction struct{}
+
+// Metadata returns the function name.
+func (f *buildRoleArnFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "build_role_arn"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *buildRoleArnFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Build the ARN of a RAM role",
+		Description: "Given the ID of an AliCloud account and the name of a RAM role, return the ARN of the role, acs:ram::<account>:role/<name>.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "account_id",
+				Description: "The ID of the AliCloud account owning the role.",
+			},
+			function.StringParameter{
+				Name:        "role_name",
+				Description: "The name of the RAM role.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the role ARN.
+func (f *buildRoleArnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var accountId, roleName string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &accountId, &roleName))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := common.ValidateAccountId(accountId); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	if err := common.ValidateRoleName(roleName); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, common.BuildRoleArn(accountId, roleName)))
+}
+
+// NewBuildOidcProviderArnFunction is a helper function to simplify the provider implementation.
+func NewBuildOidcProviderArnFunction() function.Function {
+	return &buildOidcProviderArnFunction{}
+}
+
+// buildOidcProviderArnFunction assembles the ARN of an OIDC identity provider.
+type buildOidcProviderArnFunction struct{}
+
+// Metadata returns the function name.
+func (f *buildOidcProviderArnFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "build_oidc_provider_arn"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *buildOidcProviderArnFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Build the ARN of an OIDC identity provider",
+		Description: "Given the ID of an AliCloud account and the name of an OIDC identity provider, return the ARN of the provider, " +
+			"acs:ram::<account>:oidc-provider/<name>.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "account_id",
+				Description: "The ID of the AliCloud account owning the OIDC provider.",
+			},
+			function.StringParameter{
+				Name:        "oidc_provider_name",
+				Description: "The name of the OIDC identity provider.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run builds the OIDC provider ARN.
+func (f *buildOidcProviderArnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var accountId, oidcProviderName string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &accountId, &oidcProviderName))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := common.ValidateAccountId(accountId); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	if err := common.ValidateOidcProviderName(oidcProviderName); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, common.BuildOidcProviderArn(accountId, oidcProviderName)))
+}
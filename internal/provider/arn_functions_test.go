@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// callTestFunction calls a provider function with string arguments through the protocol
+// server, as Terraform would, and returns its result or error text.
+func callTestFunction(t *testing.T, name string, returnType tftypes.Type, arguments ...string) (tftypes.Value, string) {
+	t.Helper()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("failed to create provider server: %v", err)
+	}
+
+	var dynamicArguments []*tfprotov6.DynamicValue
+	for _, argument := range arguments {
+		value, err := tfprotov6.NewDynamicValue(tftypes.String, tftypes.NewValue(tftypes.String, argument))
+		if err != nil {
+			t.Fatalf("failed to encode argument: %v", err)
+		}
+		dynamicArguments = append(dynamicArguments, &value)
+	}
+
+	resp, err := server.CallFunction(context.Background(), &tfprotov6.CallFunctionRequest{
+		Name:      name,
+		Arguments: dynamicArguments,
+	})
+	if err != nil {
+		t.Fatalf("failed to call function: %v", err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error.Text
+	}
+
+	result, err := resp.Result.Unmarshal(returnType)
+	if err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	return result, ""
+}
+
+func TestParseArnFunction(t *testing.T) {
+	returnType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"service":       tftypes.String,
+		"region":        tftypes.String,
+		"account_id":    tftypes.String,
+		"resource_type": tftypes.String,
+		"resource_name": tftypes.String,
+	}}
+
+	result, errText := callTestFunction(t, "parse_arn", returnType, "acs:ram::1234567890123456:role/visionone")
+	if errText != "" {
+		t.Fatalf("unexpected error: %s", errText)
+	}
+	expected := tftypes.NewValue(returnType, map[string]tftypes.Value{
+		"service":       tftypes.NewValue(tftypes.String, "ram"),
+		"region":        tftypes.NewValue(tftypes.String, ""),
+		"account_id":    tftypes.NewValue(tftypes.String, "1234567890123456"),
+		"resource_type": tftypes.NewValue(tftypes.String, "role"),
+		"resource_name": tftypes.NewValue(tftypes.String, "visionone"),
+	})
+	if !result.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+
+	if _, errText := callTestFunction(t, "parse_arn", returnType, "arn:aws:iam::123456789012:role/visionone"); !strings.Contains(errText, "is not an Alibaba Cloud ARN") {
+		t.Errorf("expected an invalid ARN error, got %q", errText)
+	}
+}
+
+func TestBuildArnFunctions(t *testing.T) {
+	tests := []struct {
+		function    string
+		arguments   []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			function:  "build_role_arn",
+			arguments: []string{"1234567890123456", "visionone"},
+			expected:  "acs:ram::1234567890123456:role/visionone",
+		},
+		{
+			function:    "build_role_arn",
+			arguments:   []string{"account", "visionone"},
+			expectedErr: "is not an AliCloud account ID",
+		},
+		{
+			function:    "build_role_arn",
+			arguments:   []string{"1234567890123456", "vision one"},
+			expectedErr: "is not a RAM role name",
+		},
+		{
+			function:  "build_oidc_provider_arn",
+			arguments: []string{"1234567890123456", "trendmicro-visionone"},
+			expected:  "acs:ram::1234567890123456:oidc-provider/trendmicro-visionone",
+		},
+		{
+			function:    "build_oidc_provider_arn",
+			arguments:   []string{"1234567890123456", ""},
+			expectedErr: "is not an OIDC provider name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function+"("+strings.Join(tt.arguments, ", ")+")", func(t *testing.T) {
+			result, errText := callTestFunction(t, tt.function, tftypes.String, tt.arguments...)
+			if tt.expectedErr != "" {
+				if !strings.Contains(errText, tt.expectedErr) {
+					t.Errorf("expected %q error, got %q", tt.expectedErr, errText)
+				}
+				return
+			}
+			if errText != "" {
+				t.Fatalf("unexpected error: %s", errText)
+			}
+
+			var arn string
+			if err := result.As(&arn); err != nil {
+				t.Fatalf("failed to read result: %v", err)
+			}
+			if arn != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, arn)
+			}
+		})
+	}
+}
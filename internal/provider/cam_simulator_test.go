@@ -0,0 +1,1005 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	ram "github.com/alibabacloud-go/ram-20150501/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+)
+
+const (
+	fakeCamApiKey     = "test-api-key"
+	fakeCamBusinessId = "test-business-id"
+	fakeCamRegion     = "us"
+	fakeStsAccountId  = "1234567890123456"
+)
+
+// fakeCamPathPrefixes are the paths of the Alibaba Cloud accounts API served by the fake CAM
+// server, indexed by endpoint type.
+var fakeCamPathPrefixes = map[string]string{
+	"automation": "/v3.0/cam/alibabaAccounts",
+	"express":    "/public/cam/api/ui/alibabaAccounts",
+}
+
+// fakeCamFilterCondition matches a condition of the TMV1-Filter header.
+var fakeCamFilterCondition = regexp.MustCompile(`^(\w+) eq '((?:[^']|'')*)'$`)
+
+// fakeCamAccount is an Alibaba Cloud account connected to the fake CAM server.
+type fakeCamAccount struct {
+	common.ReadConnectionResponse
+
+	// pendingStates are the states reported by the next reads of the account. The account
+	// keeps the last one.
+	pendingStates []string
+}
+
+// fakeCamFault is an error returned by the fake CAM server instead of handling a request.
+type fakeCamFault struct {
+	method string
+	status int
+	code   string
+}
+
+// fakeCamServer is an in-process fake of the Cloud Account Management API of VisionOne. It
+// serves the automation and express paths with the same accounts, so that tests can run the
+// provider without a VisionOne tenant.
+type fakeCamServer struct {
+	*httptest.Server
+
+	// CreateStates are the states reported by the reads of a new account. The last state is
+	// kept by the account once all the others have been read.
+	CreateStates []string
+	// PageSize is the number of accounts listed per page.
+	PageSize int
+	// DefaultSecurityServices are the security services that VisionOne enables on an account
+	// created without connected security services.
+	DefaultSecurityServices []common.ConnectedSecurityService
+	// ExtraInstanceIds are the instance IDs that VisionOne adds to the instances of a security
+	// service, indexed by the name of the service, when an account enables the service.
+	ExtraInstanceIds map[string][]string
+
+	mu       sync.Mutex
+	accounts map[string]*fakeCamAccount
+	faults   []fakeCamFault
+	requests []string
+}
+
+// newFakeCamServer starts a fake CAM server, closed at the end of the test.
+func newFakeCamServer(t *testing.T) *fakeCamServer {
+	t.Helper()
+
+	s := &fakeCamServer{
+		CreateStates: []string{"", common.ConnectionStateManaged},
+		PageSize:     100,
+		accounts:     map[string]*fakeCamAccount{},
+
+		DefaultSecurityServices: []common.ConnectedSecurityService{{Name: tea.String("cloud-posture")}},
+	}
+	mux := http.NewServeMux()
+	for _, prefix := range fakeCamPathPrefixes {
+		mux.HandleFunc(prefix, s.handleCollection)
+		mux.HandleFunc(prefix+"/", s.handleAccount)
+	}
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// ProviderConfig returns the provider configuration targeting the fake server with the given
+// endpoint type. The blocks are added to the provider block.
+func (s *fakeCamServer) ProviderConfig(endpointType string, blocks ...string) string {
+	return fmt.Sprintf(`
+provider "alicloudsecurity" {
+  visionone_endpoint      = %q
+  visionone_endpoint_type = %q
+  visionone_business_id   = %q
+  visionone_api_key       = %q
+  visionone_region        = %q
+  max_retries             = 0
+%s}
+`, s.URL, endpointType, fakeCamBusinessId, fakeCamApiKey, fakeCamRegion, strings.Join(blocks, ""))
+}
+
+// InjectFault makes the next request with the given method fail with the given status and
+// error code. Faults are consumed in the order they were injected.
+func (s *fakeCamServer) InjectFault(method string, status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fakeCamFault{method: method, status: status, code: code})
+}
+
+// SetState sets the state of a connected account, as if VisionOne updated it.
+func (s *fakeCamServer) SetState(accountId, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account, ok := s.accounts[accountId]; ok {
+		account.State = &state
+		account.pendingStates = nil
+	}
+}
+
+// SetLastSynced sets the last sync time of a connected account, as if VisionOne synced it then.
+func (s *fakeCamServer) SetLastSynced(accountId string, syncedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account, ok := s.accounts[accountId]; ok {
+		lastSynced := syncedAt.UTC().Truncate(time.Second)
+		account.LastSyncedDateTime = &lastSynced
+	}
+}
+
+// SetTimeZone reports the timestamps of a connected account in the given time zone, as if
+// VisionOne changed their format without changing the instants.
+func (s *fakeCamServer) SetTimeZone(accountId string, location *time.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account, ok := s.accounts[accountId]; ok {
+		for _, timestamp := range []*time.Time{account.CreatedDateTime, account.UpdatedDateTime, account.LastSyncedDateTime} {
+			if timestamp != nil {
+				*timestamp = timestamp.In(location)
+			}
+		}
+	}
+}
+
+// Account returns a copy of a connected account, nil if it is not connected.
+func (s *fakeCamServer) Account(accountId string) *common.ReadConnectionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[accountId]
+	if !ok {
+		return nil
+	}
+	response := account.ReadConnectionResponse
+	return &response
+}
+
+// Requests returns the method and path of the requests received by the server.
+func (s *fakeCamServer) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// authenticate rejects the requests without the API key and business ID of the provider, and
+// returns the injected faults.
+func (s *fakeCamServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		var fault *fakeCamFault
+		for i := range s.faults {
+			if s.faults[i].method == r.Method {
+				f := s.faults[i]
+				fault = &f
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+fakeCamApiKey || r.Header.Get("x-customer-id") != fakeCamBusinessId {
+			writeFakeCamError(w, r, http.StatusUnauthorized, "Unauthorized", "invalid API key or business ID")
+			return
+		}
+		if fault != nil {
+			writeFakeCamError(w, r, fault.status, fault.code, "injected fault")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleCollection serves the create and list operations.
+func (s *fakeCamServer) handleCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.create(w, r)
+	case http.MethodGet:
+		s.list(w, r)
+	default:
+		writeFakeCamError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not allowed")
+	}
+}
+
+// handleAccount serves the read, update and delete operations.
+func (s *fakeCamServer) handleAccount(w http.ResponseWriter, r *http.Request) {
+	accountId := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[accountId]
+	if !ok {
+		writeFakeCamError(w, r, http.StatusNotFound, "NotFound", "account "+accountId+" not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if len(account.pendingStates) > 0 {
+			account.State = &account.pendingStates[0]
+			account.pendingStates = account.pendingStates[1:]
+		}
+		writeFakeCamJSON(w, http.StatusOK, account.ReadConnectionResponse)
+	case http.MethodPatch:
+		var req common.UpdateConnectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeFakeCamError(w, r, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		if req.Name != nil {
+			account.Name = req.Name
+		}
+		if req.Description != nil {
+			account.Description = req.Description
+		}
+		if req.ConnectedSecurityServices != nil {
+			account.ConnectedSecurityServices = s.addExtraInstanceIds(*req.ConnectedSecurityServices)
+		}
+		account.UpdatedDateTime = fakeCamNow()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(s.accounts, accountId)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeFakeCamError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not allowed")
+	}
+}
+
+func (s *fakeCamServer) create(w http.ResponseWriter, r *http.Request) {
+	var req common.CreateConnectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeCamError(w, r, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+	for field, value := range map[string]*string{
+		"accountId":      req.AccountId,
+		"region":         req.Region,
+		"roleArn":        req.RoleArn,
+		"oidcProviderId": req.OidcProviderId,
+		"name":           req.Name,
+	} {
+		if value == nil || *value == "" {
+			writeFakeCamError(w, r, http.StatusBadRequest, "BadRequest", field+" is required")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[*req.AccountId]; ok {
+		writeFakeCamError(w, r, http.StatusConflict, "Conflict", "account "+*req.AccountId+" is already connected")
+		return
+	}
+
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+	securityServices := s.DefaultSecurityServices
+	if req.ConnectedSecurityServices != nil {
+		securityServices = *req.ConnectedSecurityServices
+	}
+	states := append([]string(nil), s.CreateStates...)
+	account := &fakeCamAccount{
+		ReadConnectionResponse: common.ReadConnectionResponse{
+			Id:                 req.AccountId,
+			ParentStackRegion:  req.Region,
+			RoleArn:            req.RoleArn,
+			OidcProviderId:     req.OidcProviderId,
+			Name:               req.Name,
+			Description:        &description,
+			CreatedDateTime:    fakeCamNow(),
+			UpdatedDateTime:    fakeCamNow(),
+			State:              &states[0],
+			LastSyncedDateTime: fakeCamNow(),
+
+			ConnectedSecurityServices: s.addExtraInstanceIds(securityServices),
+		},
+		pendingStates: states,
+	}
+	s.accounts[*req.AccountId] = account
+	w.WriteHeader(http.StatusCreated)
+}
+
+// addExtraInstanceIds returns the requested security services with the extra instance IDs
+// added by VisionOne. The caller must hold the lock.
+func (s *fakeCamServer) addExtraInstanceIds(services []common.ConnectedSecurityService) []common.ConnectedSecurityService {
+	if services == nil {
+		return nil
+	}
+	added := make([]common.ConnectedSecurityService, 0, len(services))
+	for _, service := range services {
+		if extra := s.ExtraInstanceIds[tea.StringValue(service.Name)]; len(extra) > 0 {
+			service.InstanceIds = append(slices.Clone(service.InstanceIds), extra...)
+		}
+		added = append(added, service)
+	}
+	return added
+}
+
+func (s *fakeCamServer) list(w http.ResponseWriter, r *http.Request) {
+	filters := map[string]string{}
+	if filter := r.Header.Get("TMV1-Filter"); filter != "" {
+		for _, condition := range strings.Split(filter, " and ") {
+			match := fakeCamFilterCondition.FindStringSubmatch(condition)
+			if match == nil {
+				writeFakeCamError(w, r, http.StatusBadRequest, "InvalidFilter", "invalid filter: "+filter)
+				return
+			}
+			filters[match[1]] = strings.ReplaceAll(match[2], "''", "'")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []*common.ReadConnectionResponse{}
+	for _, account := range s.accounts {
+		if state, ok := filters["state"]; ok && *account.State != state {
+			continue
+		}
+		if name, ok := filters["name"]; ok && *account.Name != name {
+			continue
+		}
+		item := account.ReadConnectionResponse
+		items = append(items, &item)
+	}
+	sort.Slice(items, func(i, j int) bool { return *items[i].Id < *items[j].Id })
+
+	skip := 0
+	if token := r.URL.Query().Get("skipToken"); token != "" {
+		var err error
+		if skip, err = strconv.Atoi(token); err != nil {
+			writeFakeCamError(w, r, http.StatusBadRequest, "InvalidSkipToken", "invalid skip token: "+token)
+			return
+		}
+	}
+	page := common.ListConnectionsResponse{Items: []*common.ReadConnectionResponse{}}
+	if skip < len(items) {
+		end := min(skip+s.PageSize, len(items))
+		page.Items = items[skip:end]
+		if end < len(items) {
+			token := strconv.Itoa(end)
+			page.SkipToken = &token
+		}
+	}
+	writeFakeCamJSON(w, http.StatusOK, page)
+}
+
+func fakeCamNow() *time.Time {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &now
+}
+
+func writeFakeCamJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeCamError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeFakeCamJSON(w, status, map[string]any{
+		"error": map[string]string{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// fakeAliCloudServer is an in-process fake of the AliCloud STS, RAM and IMS APIs. It answers
+// GetCallerIdentity with its account, and manages its RAM roles, their policy attachments and
+// its OIDC providers.
+type fakeAliCloudServer struct {
+	*httptest.Server
+
+	AccountId string
+
+	mu            sync.Mutex
+	roles         map[string]*fakeAliCloudRole         // The roles, indexed by role name.
+	oidcProviders map[string]*fakeAliCloudOidcProvider // The OIDC providers, indexed by name.
+	faults        []fakeAliCloudFault
+	actions       []string
+}
+
+// fakeAliCloudRole is a RAM role of the fake AliCloud server.
+type fakeAliCloudRole struct {
+	TrustPolicy        string
+	Description        string
+	MaxSessionDuration int64
+	Policies           map[fakeAliCloudPolicy]bool // The policies attached to the role.
+}
+
+// fakeAliCloudPolicy identifies a RAM policy attached to a role.
+type fakeAliCloudPolicy struct {
+	Type string // System or Custom.
+	Name string
+}
+
+// fakeAliCloudOidcProvider is an OIDC identity provider of the fake AliCloud server.
+type fakeAliCloudOidcProvider struct {
+	IssuerUrl    string
+	Fingerprints []string
+	ClientIds    []string
+	Description  string
+}
+
+// fakeAliCloudFault is an error returned by the fake AliCloud server instead of handling an
+// action.
+type fakeAliCloudFault struct {
+	action string
+	status int
+	code   string
+}
+
+// newFakeAliCloudServer starts a fake AliCloud server of the given account, closed at the end
+// of the test.
+func newFakeAliCloudServer(t *testing.T, accountId string) *fakeAliCloudServer {
+	t.Helper()
+
+	s := &fakeAliCloudServer{
+		AccountId:     accountId,
+		roles:         map[string]*fakeAliCloudRole{},
+		oidcProviders: map[string]*fakeAliCloudOidcProvider{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// ProviderConfig returns the alicloud block of the provider targeting the fake server.
+func (s *fakeAliCloudServer) ProviderConfig() string {
+	return fmt.Sprintf(`
+  alicloud {
+    access_key   = "test-access-key"
+    secret_key   = "test-access-secret"
+    region       = "cn-hangzhou"
+    sts_endpoint = %q
+    ram_endpoint = %q
+    ims_endpoint = %q
+  }
+`, s.URL, s.URL, s.URL)
+}
+
+// PutRole creates or replaces a role with the given trust policy.
+func (s *fakeAliCloudServer) PutRole(roleName, trustPolicy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[roleName] = &fakeAliCloudRole{
+		TrustPolicy:        trustPolicy,
+		MaxSessionDuration: 3600,
+		Policies:           map[fakeAliCloudPolicy]bool{},
+	}
+}
+
+// Role returns a copy of a role, nil if it does not exist.
+func (s *fakeAliCloudServer) Role(roleName string) *fakeAliCloudRole {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	role, ok := s.roles[roleName]
+	if !ok {
+		return nil
+	}
+	copied := *role
+	copied.Policies = maps.Clone(role.Policies)
+	return &copied
+}
+
+// OidcProvider returns a copy of an OIDC provider, nil if it does not exist.
+func (s *fakeAliCloudServer) OidcProvider(name string) *fakeAliCloudOidcProvider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider, ok := s.oidcProviders[name]
+	if !ok {
+		return nil
+	}
+	copied := *provider
+	copied.Fingerprints = slices.Clone(provider.Fingerprints)
+	copied.ClientIds = slices.Clone(provider.ClientIds)
+	return &copied
+}
+
+// InjectFault makes the next call of the given action fail with the given status and error
+// code. Faults are consumed in the order they were injected.
+func (s *fakeAliCloudServer) InjectFault(action string, status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fakeAliCloudFault{action: action, status: status, code: code})
+}
+
+// Actions returns the actions called on the server.
+func (s *fakeAliCloudServer) Actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.actions...)
+}
+
+func (s *fakeAliCloudServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The RPC actions are sent either as an Action parameter or as an x-acs-action header
+	action := r.FormValue("Action")
+	if action == "" {
+		action = r.Header.Get("x-acs-action")
+	}
+	s.actions = append(s.actions, action)
+
+	for i, fault := range s.faults {
+		if fault.action == action {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			writeFakeAliCloudError(w, fault.status, fault.code, "injected fault")
+			return
+		}
+	}
+
+	switch action {
+	case "GetCallerIdentity":
+		writeFakeCamJSON(w, http.StatusOK, map[string]string{
+			"AccountId":    s.AccountId,
+			"Arn":          "acs:ram::" + s.AccountId + ":root",
+			"IdentityType": "Account",
+			"PrincipalId":  s.AccountId,
+			"UserId":       s.AccountId,
+			"RequestId":    common.GenerateUUID(),
+		})
+	case "CreateRole", "GetRole", "UpdateRole", "DeleteRole":
+		s.handleRole(w, r, action)
+	case "AttachPolicyToRole", "DetachPolicyFromRole", "ListPoliciesForRole":
+		s.handleRolePolicies(w, r, action)
+	case "CreateOIDCProvider", "GetOIDCProvider", "UpdateOIDCProvider", "AddFingerprintToOIDCProvider",
+		"RemoveFingerprintFromOIDCProvider", "DeleteOIDCProvider":
+		s.handleOidcProvider(w, r, action)
+	default:
+		writeFakeAliCloudError(w, http.StatusBadRequest, "InvalidAction.NotFound", "Specified api is not found: "+action)
+	}
+}
+
+// handleRole serves the actions managing the RAM roles.
+func (s *fakeAliCloudServer) handleRole(w http.ResponseWriter, r *http.Request, action string) {
+	roleName := r.FormValue("RoleName")
+	role, ok := s.roles[roleName]
+
+	switch action {
+	case "CreateRole":
+		if ok {
+			writeFakeAliCloudError(w, http.StatusConflict, "EntityAlreadyExists.Role", "The role already exists: "+roleName)
+			return
+		}
+		role = &fakeAliCloudRole{
+			TrustPolicy:        r.FormValue("AssumeRolePolicyDocument"),
+			Description:        r.FormValue("Description"),
+			MaxSessionDuration: 3600,
+			Policies:           map[fakeAliCloudPolicy]bool{},
+		}
+		if value := r.FormValue("MaxSessionDuration"); value != "" {
+			role.MaxSessionDuration, _ = strconv.ParseInt(value, 10, 64)
+		}
+		s.roles[roleName] = role
+	case "GetRole":
+		if !ok {
+			writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.Role", "The role does not exist: "+roleName)
+			return
+		}
+	case "UpdateRole":
+		if !ok {
+			writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.Role", "The role does not exist: "+roleName)
+			return
+		}
+		if value := r.FormValue("NewAssumeRolePolicyDocument"); value != "" {
+			role.TrustPolicy = value
+		}
+		if value := r.FormValue("NewDescription"); value != "" {
+			role.Description = value
+		}
+		if value := r.FormValue("NewMaxSessionDuration"); value != "" {
+			role.MaxSessionDuration, _ = strconv.ParseInt(value, 10, 64)
+		}
+	case "DeleteRole":
+		if !ok {
+			writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.Role", "The role does not exist: "+roleName)
+			return
+		}
+		if len(role.Policies) > 0 {
+			writeFakeAliCloudError(w, http.StatusConflict, "DeleteConflict.Role.Policy", "The role still has policies attached: "+roleName)
+			return
+		}
+		delete(s.roles, roleName)
+		writeFakeCamJSON(w, http.StatusOK, map[string]string{"RequestId": common.GenerateUUID()})
+		return
+	}
+
+	writeFakeCamJSON(w, http.StatusOK, map[string]any{
+		"RequestId": common.GenerateUUID(),
+		"Role": map[string]any{
+			"RoleId":                   "3" + s.AccountId,
+			"RoleName":                 roleName,
+			"Arn":                      "acs:ram::" + s.AccountId + ":role/" + strings.ToLower(roleName),
+			"Description":              role.Description,
+			"AssumeRolePolicyDocument": role.TrustPolicy,
+			"MaxSessionDuration":       role.MaxSessionDuration,
+		},
+	})
+}
+
+// handleRolePolicies serves the actions managing the policies attached to the RAM roles.
+func (s *fakeAliCloudServer) handleRolePolicies(w http.ResponseWriter, r *http.Request, action string) {
+	roleName := r.FormValue("RoleName")
+	role, ok := s.roles[roleName]
+	if !ok {
+		writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.Role", "The role does not exist: "+roleName)
+		return
+	}
+	policy := fakeAliCloudPolicy{Type: r.FormValue("PolicyType"), Name: r.FormValue("PolicyName")}
+
+	switch action {
+	case "AttachPolicyToRole":
+		if role.Policies[policy] {
+			writeFakeAliCloudError(w, http.StatusConflict, "EntityAlreadyExists.Role.Policy", "The policy is already attached: "+policy.Name)
+			return
+		}
+		role.Policies[policy] = true
+	case "DetachPolicyFromRole":
+		if !role.Policies[policy] {
+			writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.Role.Policy", "The policy is not attached: "+policy.Name)
+			return
+		}
+		delete(role.Policies, policy)
+	case "ListPoliciesForRole":
+		policies := []map[string]string{}
+		for policy := range role.Policies {
+			policies = append(policies, map[string]string{"PolicyType": policy.Type, "PolicyName": policy.Name})
+		}
+		sort.Slice(policies, func(i, j int) bool { return policies[i]["PolicyName"] < policies[j]["PolicyName"] })
+		writeFakeCamJSON(w, http.StatusOK, map[string]any{
+			"RequestId": common.GenerateUUID(),
+			"Policies":  map[string]any{"Policy": policies},
+		})
+		return
+	}
+	writeFakeCamJSON(w, http.StatusOK, map[string]string{"RequestId": common.GenerateUUID()})
+}
+
+// handleOidcProvider serves the IMS actions managing the OIDC providers.
+func (s *fakeAliCloudServer) handleOidcProvider(w http.ResponseWriter, r *http.Request, action string) {
+	name := r.FormValue("OIDCProviderName")
+	provider, ok := s.oidcProviders[name]
+	if !ok && action != "CreateOIDCProvider" {
+		writeFakeAliCloudError(w, http.StatusNotFound, "EntityNotExist.OIDCProvider", "The OIDC provider does not exist: "+name)
+		return
+	}
+
+	switch action {
+	case "CreateOIDCProvider":
+		if ok {
+			writeFakeAliCloudError(w, http.StatusConflict, "EntityAlreadyExists.OIDCProvider", "The OIDC provider already exists: "+name)
+			return
+		}
+		provider = &fakeAliCloudOidcProvider{
+			IssuerUrl:    r.FormValue("IssuerUrl"),
+			Fingerprints: splitFakeAliCloudList(r.FormValue("Fingerprints")),
+			ClientIds:    splitFakeAliCloudList(r.FormValue("ClientIds")),
+			Description:  r.FormValue("Description"),
+		}
+		s.oidcProviders[name] = provider
+	case "UpdateOIDCProvider":
+		provider.ClientIds = splitFakeAliCloudList(r.FormValue("ClientIds"))
+	case "AddFingerprintToOIDCProvider":
+		if fingerprint := r.FormValue("Fingerprint"); !slices.Contains(provider.Fingerprints, fingerprint) {
+			provider.Fingerprints = append(provider.Fingerprints, fingerprint)
+		}
+	case "RemoveFingerprintFromOIDCProvider":
+		provider.Fingerprints = slices.DeleteFunc(provider.Fingerprints, func(fingerprint string) bool {
+			return fingerprint == r.FormValue("Fingerprint")
+		})
+	case "DeleteOIDCProvider":
+		delete(s.oidcProviders, name)
+		writeFakeCamJSON(w, http.StatusOK, map[string]string{"RequestId": common.GenerateUUID()})
+		return
+	}
+
+	writeFakeCamJSON(w, http.StatusOK, map[string]any{
+		"RequestId": common.GenerateUUID(),
+		"OIDCProvider": map[string]string{
+			"Arn":              common.BuildOidcProviderArn(s.AccountId, name),
+			"OIDCProviderName": name,
+			"IssuerUrl":        provider.IssuerUrl,
+			"Fingerprints":     strings.Join(provider.Fingerprints, ","),
+			"ClientIds":        strings.Join(provider.ClientIds, ","),
+			"Description":      provider.Description,
+		},
+	})
+}
+
+// splitFakeAliCloudList splits a comma separated list parameter.
+func splitFakeAliCloudList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func writeFakeAliCloudError(w http.ResponseWriter, status int, code, message string) {
+	writeFakeCamJSON(w, status, map[string]string{
+		"Code":      code,
+		"Message":   message,
+		"RequestId": common.GenerateUUID(),
+	})
+}
+
+// newTestFakeCamClient returns a CamClient that sends requests to the fake server with the
+// given endpoint type.
+func newTestFakeCamClient(t *testing.T, s *fakeCamServer, endpointType string) *common.CamClient {
+	t.Helper()
+
+	clients := &common.VisionOneClients{RetryPolicy: &common.RetryPolicy{}}
+	if _, err := clients.Build(context.Background(), s.URL, endpointType, fakeCamBusinessId, fakeCamApiKey, fakeCamRegion); err != nil {
+		t.Fatalf("failed to create CAM client: %v", err)
+	}
+	return clients.Cam
+}
+
+func TestFakeCamServerLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	for endpointType := range fakeCamPathPrefixes {
+		t.Run(endpointType, func(t *testing.T) {
+			cam := newFakeCamServer(t)
+			cam.PageSize = 1
+			client := newTestFakeCamClient(t, cam, endpointType)
+
+			for _, accountId := range []string{"1111111111111111", "2222222222222222"} {
+				err := client.CreateConnection(ctx, &common.CreateConnectionRequest{
+					AccountId:      &accountId,
+					Region:         tea.String("us-east-1"),
+					RoleArn:        tea.String("acs:ram::" + accountId + ":role/visionone"),
+					OidcProviderId: tea.String("trendmicro-visionone"),
+					Name:           tea.String("test-" + accountId),
+				})
+				if err != nil {
+					t.Fatalf("failed to create connection: %v", err)
+				}
+			}
+
+			accountId := "1111111111111111"
+			err := client.CreateConnection(ctx, &common.CreateConnectionRequest{
+				AccountId:      &accountId,
+				Region:         tea.String("us-east-1"),
+				RoleArn:        tea.String("acs:ram::" + accountId + ":role/visionone"),
+				OidcProviderId: tea.String("trendmicro-visionone"),
+				Name:           tea.String("test"),
+			})
+			if !common.IsCamAPIErrorStatus(err, http.StatusConflict) {
+				t.Fatalf("expected a conflict error, got %v", err)
+			}
+
+			// The account is pending until the second read
+			for _, expected := range []string{"", common.ConnectionStateManaged, common.ConnectionStateManaged} {
+				resp, err := client.ReadConnection(ctx, &accountId)
+				if err != nil {
+					t.Fatalf("failed to read connection: %v", err)
+				}
+				if *resp.State != expected {
+					t.Fatalf("expected state %q, got %q", expected, *resp.State)
+				}
+			}
+
+			if err := client.UpdateConnection(ctx, &accountId, &common.UpdateConnectionRequest{Name: tea.String("renamed")}); err != nil {
+				t.Fatalf("failed to update connection: %v", err)
+			}
+			if name := *cam.Account(accountId).Name; name != "renamed" {
+				t.Fatalf("expected name renamed, got %q", name)
+			}
+
+			connections, err := client.ListConnections(ctx, &common.ListConnectionsRequest{})
+			if err != nil {
+				t.Fatalf("failed to list connections: %v", err)
+			}
+			if len(connections) != 2 {
+				t.Fatalf("expected 2 connections, got %d", len(connections))
+			}
+			connections, err = client.ListConnections(ctx, &common.ListConnectionsRequest{Name: tea.String("renamed")})
+			if err != nil {
+				t.Fatalf("failed to list connections: %v", err)
+			}
+			if len(connections) != 1 || *connections[0].Id != accountId {
+				t.Fatalf("expected the renamed connection, got %d connections", len(connections))
+			}
+
+			if err := client.DeleteConnection(ctx, &accountId); err != nil {
+				t.Fatalf("failed to delete connection: %v", err)
+			}
+			if resp, err := client.ReadConnection(ctx, &accountId); err != nil || resp != nil {
+				t.Fatalf("expected the connection to be deleted, got %+v, %v", resp, err)
+			}
+
+			for _, request := range cam.Requests() {
+				if !strings.Contains(request, fakeCamPathPrefixes[endpointType]) {
+					t.Errorf("unexpected request path for %s endpoint: %s", endpointType, request)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeCamServerInjectFault(t *testing.T) {
+	cam := newFakeCamServer(t)
+	cam.InjectFault(http.MethodGet, http.StatusServiceUnavailable, "ServiceUnavailable")
+	client := newTestFakeCamClient(t, cam, "automation")
+
+	accountId := "1111111111111111"
+	_, err := client.ReadConnection(context.Background(), &accountId)
+	var camErr *common.CamAPIError
+	if !errors.As(err, &camErr) || camErr.Code != "ServiceUnavailable" {
+		t.Fatalf("expected the injected fault, got %v", err)
+	}
+
+	// The fault is only returned once
+	if resp, err := client.ReadConnection(context.Background(), &accountId); err != nil || resp != nil {
+		t.Fatalf("expected a not found account, got %+v, %v", resp, err)
+	}
+}
+
+func TestFakeCamServerRejectsInvalidApiKey(t *testing.T) {
+	cam := newFakeCamServer(t)
+	clients := &common.VisionOneClients{RetryPolicy: &common.RetryPolicy{}}
+	client, err := clients.BuildCamClient(context.Background(), cam.URL, "automation", fakeCamBusinessId, "invalid", fakeCamRegion)
+	if err != nil {
+		t.Fatalf("failed to create CAM client: %v", err)
+	}
+
+	accountId := "1111111111111111"
+	if _, err := client.ReadConnection(context.Background(), &accountId); !common.IsCamAPIErrorStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestFakeAliCloudServerGetCallerIdentity(t *testing.T) {
+	sts := newFakeAliCloudServer(t, fakeStsAccountId)
+	clients := &common.AliCloudClients{
+		Config: &common.AliCloudClientConfig{
+			AccessKey:       "test-access-key",
+			AccessKeySecret: "test-access-secret",
+			Region:          "cn-hangzhou",
+			StsEndpoint:     sts.URL,
+		},
+	}
+
+	accountId, err := clients.CallerAccountId()
+	if err != nil {
+		t.Fatalf("failed to get caller account: %v", err)
+	}
+	if accountId != fakeStsAccountId {
+		t.Fatalf("expected account %s, got %s", fakeStsAccountId, accountId)
+	}
+}
+
+func TestFakeAliCloudServerTrustLifecycle(t *testing.T) {
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	clients := &common.AliCloudClients{
+		Config: &common.AliCloudClientConfig{
+			AccessKey:       "test-access-key",
+			AccessKeySecret: "test-access-secret",
+			Region:          "cn-hangzhou",
+			StsEndpoint:     ali.URL,
+			RamEndpoint:     ali.URL,
+			ImsEndpoint:     ali.URL,
+		},
+	}
+	if _, err := clients.Build(); err != nil {
+		t.Fatalf("failed to build clients: %v", err)
+	}
+
+	provider, err := clients.Ims.CreateOidcProvider(&common.CreateOidcProviderRequest{
+		OIDCProviderName: "visionone",
+		IssuerUrl:        "https://cloudaccounts-us.visionone.trendmicro.com",
+		Fingerprints:     []string{"fingerprint-1"},
+		ClientIds:        []string{fakeCamBusinessId},
+	})
+	if err != nil {
+		t.Fatalf("failed to create OIDC provider: %v", err)
+	}
+	if provider.Arn != common.BuildOidcProviderArn(fakeStsAccountId, "visionone") || !slices.Equal(provider.ClientIds, []string{fakeCamBusinessId}) {
+		t.Fatalf("unexpected OIDC provider: %+v", provider)
+	}
+	if err := clients.Ims.AddOidcProviderFingerprint("visionone", "fingerprint-2"); err != nil {
+		t.Fatalf("failed to add fingerprint: %v", err)
+	}
+	if err := clients.Ims.RemoveOidcProviderFingerprint("visionone", "fingerprint-1"); err != nil {
+		t.Fatalf("failed to remove fingerprint: %v", err)
+	}
+	if provider, err = clients.Ims.GetOidcProvider("visionone"); err != nil || !slices.Equal(provider.Fingerprints, []string{"fingerprint-2"}) {
+		t.Fatalf("expected the fingerprints to be replaced, got %+v, %v", provider, err)
+	}
+
+	createRoleResp, err := clients.Ram.CreateRole(&ram.CreateRoleRequest{
+		RoleName:                 tea.String("visionone"),
+		AssumeRolePolicyDocument: tea.String(`{"Version":"1"}`),
+		MaxSessionDuration:       tea.Int64(7200),
+	})
+	if err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	if arn := tea.StringValue(createRoleResp.Body.Role.Arn); arn != "acs:ram::"+fakeStsAccountId+":role/visionone" {
+		t.Fatalf("unexpected role ARN %s", arn)
+	}
+	if _, err := clients.Ram.AttachPolicyToRole(&ram.AttachPolicyToRoleRequest{
+		RoleName: tea.String("visionone"), PolicyName: tea.String("ReadOnlyAccess"), PolicyType: tea.String("System"),
+	}); err != nil {
+		t.Fatalf("failed to attach policy: %v", err)
+	}
+	listResp, err := clients.Ram.ListPoliciesForRole(&ram.ListPoliciesForRoleRequest{RoleName: tea.String("visionone")})
+	if err != nil {
+		t.Fatalf("failed to list policies: %v", err)
+	}
+	if policies := listResp.Body.Policies.Policy; len(policies) != 1 || tea.StringValue(policies[0].PolicyName) != "ReadOnlyAccess" {
+		t.Fatalf("expected the ReadOnlyAccess policy, got %+v", policies)
+	}
+
+	// A role cannot be deleted with policies attached
+	if _, err := clients.Ram.DeleteRole(&ram.DeleteRoleRequest{RoleName: tea.String("visionone")}); err == nil {
+		t.Fatal("expected the deletion of a role with policies to fail")
+	}
+	if _, err := clients.Ram.DetachPolicyFromRole(&ram.DetachPolicyFromRoleRequest{
+		RoleName: tea.String("visionone"), PolicyName: tea.String("ReadOnlyAccess"), PolicyType: tea.String("System"),
+	}); err != nil {
+		t.Fatalf("failed to detach policy: %v", err)
+	}
+	if _, err := clients.Ram.DeleteRole(&ram.DeleteRoleRequest{RoleName: tea.String("visionone")}); err != nil {
+		t.Fatalf("failed to delete role: %v", err)
+	}
+	if _, err := clients.Ram.GetRole(&ram.GetRoleRequest{RoleName: tea.String("visionone")}); !common.IsAliCloudNotFoundError(err) {
+		t.Fatalf("expected the role to be deleted, got %v", err)
+	}
+
+	if err := clients.Ims.DeleteOidcProvider("visionone"); err != nil {
+		t.Fatalf("failed to delete OIDC provider: %v", err)
+	}
+	if provider, err := clients.Ims.GetOidcProvider("visionone"); err != nil || provider != nil {
+		t.Fatalf("expected the OIDC provider to be deleted, got %+v, %v", provider, err)
+	}
+}
+
+func TestFakeAliCloudServerInjectFault(t *testing.T) {
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	ali.InjectFault("GetRole", http.StatusForbidden, "NoPermission")
+	ali.PutRole("visionone", `{"Version":"1"}`)
+	clients := &common.AliCloudClients{
+		Config: &common.AliCloudClientConfig{
+			AccessKey:       "test-access-key",
+			AccessKeySecret: "test-access-secret",
+			Region:          "cn-hangzhou",
+			RamEndpoint:     ali.URL,
+		},
+	}
+	client, err := clients.BuildRamClient(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to build RAM client: %v", err)
+	}
+
+	if _, err := client.GetRole(&ram.GetRoleRequest{RoleName: tea.String("visionone")}); err == nil || !strings.Contains(err.Error(), "NoPermission") {
+		t.Fatalf("expected the injected fault, got %v", err)
+	}
+	// The fault is only returned once
+	if _, err := client.GetRole(&ram.GetRoleRequest{RoleName: tea.String("visionone")}); err != nil {
+		t.Fatalf("failed to get role: %v", err)
+	}
+}
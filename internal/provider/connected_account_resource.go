@@ -2,10 +2,27 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"terraform-provider-alicloudsecurity/internal/common"
+	"time"
 
+	ram "github.com/alibabacloud-go/ram-20150501/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -13,6 +30,9 @@ var (
 	_ resource.Resource                = &connectedAccountResource{}
 	_ resource.ResourceWithConfigure   = &connectedAccountResource{}
 	_ resource.ResourceWithImportState = &connectedAccountResource{}
+
+	_ resource.ResourceWithConfigValidators = &connectedAccountResource{}
+	_ resource.ResourceWithModifyPlan       = &connectedAccountResource{}
 )
 
 // NewConnectedAccountResource is a helper function to simplify the provider implementation.
@@ -22,7 +42,8 @@ func NewConnectedAccountResource() resource.Resource {
 
 // connectedAccountResource is the resource implementation.
 type connectedAccountResource struct {
-	cam *common.CamClient
+	cam      *common.CamClient
+	alicloud *common.AliCloudClients
 }
 
 // connectedAccountResourceModel maps the resource schema.
@@ -34,14 +55,160 @@ type connectedAccountResourceModel struct {
 	Name             types.String `tfsdk:"name"`               // The name of the connected account in VisionOne. *required*
 	Description      types.String `tfsdk:"description"`        // The description of the connected account in VisionOne
 
-	ConnectionState types.String `tfsdk:"connection_state"`  // The state of the connected account in VisionOne
-	CreatedDateTime types.String `tfsdk:"created_date_time"` // The creation time of the connected account in VisionOne
-	UpdatedDateTime types.String `tfsdk:"updated_date_time"` // The last update time of the connected account in VisionOne
+	ConnectedSecurityServices types.Set `tfsdk:"connected_security_services"` // The VisionOne security services enabled on the AliCloud Account
+
+	VerifyAliCloudTrust types.Bool   `tfsdk:"verify_alicloud_trust"` // Whether to verify the role and OIDC provider in AliCloud before connecting the account
+	AdoptExisting       types.Bool   `tfsdk:"adopt_existing"`        // Whether to adopt the connection of an account already connected with the same region, role and OIDC provider
+	SyncStaleThreshold  types.String `tfsdk:"sync_stale_threshold"`  // How long after the last sync the connected account is considered stale
+
+	ConnectionState types.String      `tfsdk:"connection_state"`  // The state of the connected account in VisionOne
+	CreatedDateTime timetypes.RFC3339 `tfsdk:"created_date_time"` // The creation time of the connected account in VisionOne
+	UpdatedDateTime timetypes.RFC3339 `tfsdk:"updated_date_time"` // The last update time of the connected account in VisionOne
+
+	LastSyncedDateTime timetypes.RFC3339 `tfsdk:"last_synced_date_time"` // The last time VisionOne synced the connected account
+	SyncStale          types.Bool        `tfsdk:"sync_stale"`            // Whether the last sync is older than the sync_stale_threshold
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+const (
+	// defaultCreateTimeout is how long Create waits for the connection to reach a terminal state.
+	defaultCreateTimeout = 10 * time.Minute
+)
+
+// connectionStatePollInterval is how often the state of a new connection is polled.
+var connectionStatePollInterval = 5 * time.Second
+
+// nullConnectedAccountTimeouts returns the timeouts value of a resource without a timeouts block.
+func nullConnectedAccountTimeouts() timeouts.Value {
+	return timeouts.Value{
+		Object: types.ObjectNull(map[string]attr.Type{
+			"create": types.StringType,
+		}),
+	}
+}
+
+// connectionSyncHealth returns the last sync time of a connection, null if it never synced, and
+// whether the connection is stale according to the threshold, DefaultSyncStaleThreshold if null.
+func connectionSyncHealth(connection *common.ReadConnectionResponse, threshold types.String) (timetypes.RFC3339, types.Bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	lastSyncedDateTime := timetypes.NewRFC3339TimePointerValue(connection.LastSyncedDateTime)
+
+	staleThreshold := common.DefaultSyncStaleThreshold
+	if !threshold.IsNull() && !threshold.IsUnknown() {
+		parsed, err := time.ParseDuration(threshold.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("sync_stale_threshold"),
+				"Invalid Sync Stale Threshold",
+				fmt.Sprintf("Failed to parse the sync_stale_threshold %q: %v", threshold.ValueString(), err),
+			)
+			return lastSyncedDateTime, types.BoolNull(), diags
+		}
+		staleThreshold = parsed
+	}
+
+	return lastSyncedDateTime, types.BoolValue(connection.IsSyncStale(staleThreshold, time.Now())), diags
 }
 
 type ConnectedSecurityServiceModel struct {
 	Name        types.String `tfsdk:"name"`         // The name of the connected security service
-	InstanceIds types.List   `tfsdk:"instance_ids"` // The list of instance IDs of the connected security service
+	InstanceIds types.Set    `tfsdk:"instance_ids"` // The instance IDs of the connected security service
+}
+
+// connectedSecurityServiceAttributeTypes are the attributes of the connected_security_services objects.
+var connectedSecurityServiceAttributeTypes = map[string]attr.Type{
+	"name":         types.StringType,
+	"instance_ids": types.SetType{ElemType: types.StringType},
+}
+
+// expandConnectedSecurityServices converts the connected_security_services set to the services of
+// a CAM request, nil if the set is null or unknown.
+func expandConnectedSecurityServices(ctx context.Context, set types.Set) ([]common.ConnectedSecurityService, diag.Diagnostics) {
+	if set.IsNull() || set.IsUnknown() {
+		return nil, nil
+	}
+
+	var models []ConnectedSecurityServiceModel
+	diags := set.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	services := make([]common.ConnectedSecurityService, 0, len(models))
+	for _, model := range models {
+		service := common.ConnectedSecurityService{
+			Name: model.Name.ValueStringPointer(),
+		}
+		if !model.InstanceIds.IsNull() && !model.InstanceIds.IsUnknown() {
+			diags.Append(model.InstanceIds.ElementsAs(ctx, &service.InstanceIds, false)...)
+		}
+		services = append(services, service)
+	}
+	return services, diags
+}
+
+// flattenConnectedSecurityServices converts the services of a CAM response to the
+// connected_security_services set. No services are reported as an empty set.
+func flattenConnectedSecurityServices(ctx context.Context, services []common.ConnectedSecurityService) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	models := make([]ConnectedSecurityServiceModel, 0, len(services))
+	for _, service := range services {
+		model := ConnectedSecurityServiceModel{
+			Name:        types.StringValue(tea.StringValue(service.Name)),
+			InstanceIds: types.SetNull(types.StringType),
+		}
+		if service.InstanceIds != nil {
+			instanceIds, d := types.SetValueFrom(ctx, types.StringType, service.InstanceIds)
+			diags.Append(d...)
+			model.InstanceIds = instanceIds
+		}
+		models = append(models, model)
+	}
+
+	set, d := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: connectedSecurityServiceAttributeTypes}, models)
+	diags.Append(d...)
+	return set, diags
+}
+
+// keepConfiguredInstanceIds returns the services of a CAM response with the configured
+// instance_ids of a service when VisionOne reports them along with instances of its own, so
+// that the configuration keeps matching the state. The instances of VisionOne are then not
+// reported at all, while missing configured instances are.
+func keepConfiguredInstanceIds(ctx context.Context, configured types.Set, services []common.ConnectedSecurityService) ([]common.ConnectedSecurityService, diag.Diagnostics) {
+	if configured.IsNull() || configured.IsUnknown() {
+		return services, nil
+	}
+
+	var models []ConnectedSecurityServiceModel
+	diags := configured.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return services, diags
+	}
+
+	configuredInstanceIds := map[string][]string{}
+	for _, model := range models {
+		if model.InstanceIds.IsNull() || model.InstanceIds.IsUnknown() {
+			continue
+		}
+		instanceIds := []string{}
+		diags.Append(model.InstanceIds.ElementsAs(ctx, &instanceIds, false)...)
+		configuredInstanceIds[model.Name.ValueString()] = instanceIds
+	}
+
+	kept := make([]common.ConnectedSecurityService, 0, len(services))
+	for _, service := range services {
+		instanceIds, ok := configuredInstanceIds[tea.StringValue(service.Name)]
+		if ok && !slices.ContainsFunc(instanceIds, func(instanceId string) bool {
+			return !slices.Contains(service.InstanceIds, instanceId)
+		}) {
+			service.InstanceIds = instanceIds
+		}
+		kept = append(kept, service)
+	}
+	return kept, diags
 }
 
 // Metadata returns the resource type name.
@@ -50,54 +217,187 @@ func (r *connectedAccountResource) Metadata(_ context.Context, req resource.Meta
 }
 
 // Schema defines the schema for the resource.
-func (r *connectedAccountResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *connectedAccountResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "The resource schema for connected account.",
 		Attributes: map[string]schema.Attribute{
 			"stack_state_region": schema.StringAttribute{
-				Description: "The region of the AliCloud Account where the terraform state is located. *required*", // example: us-west-1
+				Description: "The region of the AliCloud Account where the terraform state is located. Changing this forces a new connection. *required*", // example: us-west-1
 				Required:    true,
+				Validators:  aliCloudRegionValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"account_id": schema.StringAttribute{
-				Description: "The ID of the AliCloud Account.",
+				Description: "The ID of the AliCloud Account. Changing this forces a new connection.",
 				Required:    true,
+				Validators:  accountIdValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"role_arn": schema.StringAttribute{
-				Description: "The ARN of the role in AliCloud Account. *required*",
+				Description: "The ARN of the role in AliCloud Account, like acs:ram::<account_id>:role/<name>. Changing this forces a new connection. *required*",
 				Required:    true,
+				Validators:  roleArnValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"oidc_provider_id": schema.StringAttribute{
-				Description: "The ID of the OIDC provider in AliCloud Account. *required*",
+				Description: "The ID of the OIDC provider in AliCloud Account. Changing this forces a new connection. *required*",
 				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 254),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Description: "The name of the connected account in VisionOne. *required*",
 				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 254),
+				},
 			},
 			"description": schema.StringAttribute{
-				Description: "The description of the connected account in VisionOne",
+				Description: "The description of the connected account in VisionOne. Defaults to an empty description.",
 				Optional:    true,
 				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Validators: []validator.String{
+					stringvalidator.LengthAtMost(254),
+				},
+			},
+			"connected_security_services": schema.SetNestedAttribute{
+				Description: "The VisionOne security services enabled on the AliCloud Account, such as cloud posture or agentless vulnerability " +
+					"and threat detection. Defaults to the services enabled by VisionOne. Set to an empty set to disable all of them.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The name of the security service in VisionOne.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthBetween(1, 254),
+							},
+						},
+						"instance_ids": schema.SetAttribute{
+							Description: "The IDs of the instances of the security service protecting the AliCloud Account. " +
+								"Defaults to the instances assigned by VisionOne. When set, the state keeps these IDs as long as VisionOne " +
+								"reports all of them: the instances that VisionOne adds to the service are not reported, and their changes " +
+								"are not detected as drift. A configured instance missing in VisionOne is detected as drift.",
+							ElementType: types.StringType,
+							Optional:    true,
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"verify_alicloud_trust": schema.BoolAttribute{
+				Description: "Verify with the AliCloud credentials of the provider, before connecting the account, that the credentials belong to account_id, " +
+					"that the role_arn role exists and that it trusts the oidc_provider_id OIDC provider. Defaults to false.",
+				Optional: true,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "When true, adopt the connection of an account already connected to VisionOne, such as one created by an apply that timed out, " +
+					"when its stack_state_region, role_arn and oidc_provider_id match the configuration. The name, description and connected_security_services are then updated. " +
+					"Otherwise the apply fails when the account is already connected, since it may be managed by another workspace. Defaults to false.",
+				Optional: true,
 			},
 			"connection_state": schema.StringAttribute{
 				Description: "The state of the connected account in VisionOne",
-				Optional:    true,
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"created_date_time": schema.StringAttribute{
-				Description: "The creation time of the connected account in VisionOne",
-				Optional:    true,
+				Description: "The creation time of the connected account in VisionOne, an RFC3339 timestamp",
+				CustomType:  timetypes.RFC3339Type{},
 				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"updated_date_time": schema.StringAttribute{
-				Description: "The last update time of the connected account in VisionOne",
+				Description: "The last update time of the connected account in VisionOne, an RFC3339 timestamp",
+				CustomType:  timetypes.RFC3339Type{},
+				Computed:    true,
+				// VisionOne changes the value on every update, ModifyPlan plans it as unknown then
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"sync_stale_threshold": schema.StringAttribute{
+				Description: "How long after the last sync, like 24h or 90m, the connected account is considered stale. Defaults to 24h.",
 				Optional:    true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"last_synced_date_time": schema.StringAttribute{
+				Description: "The last time VisionOne synced the resources of the connected account, an RFC3339 timestamp. Null until the first sync.",
+				CustomType:  timetypes.RFC3339Type{},
+				Computed:    true,
+			},
+			"sync_stale": schema.BoolAttribute{
+				Description: "Whether the last sync of the connected account, or its creation if it was never synced, is older than sync_stale_threshold.",
 				Computed:    true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+	}
+}
+
+// ConfigValidators returns the validations of the resource configuration across attributes.
+func (r *connectedAccountResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		roleArnAccountValidator{
+			accountIdPath: path.Root("account_id"),
+			roleArnPath:   path.Root("role_arn"),
+		},
+	}
+}
+
+// ModifyPlan plans an unknown updated_date_time when the connection is updated in VisionOne.
+func (r *connectedAccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on create and destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state connectedAccountResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if connectionChanged(&plan, &state) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("updated_date_time"), timetypes.NewRFC3339Unknown())...)
 	}
 }
 
+// connectionChanged reports whether the plan changes the attributes of the connection that
+// are updated in VisionOne, as opposed to the attributes only used by the provider. The other
+// attributes of the connection force a new connection.
+func connectionChanged(plan, state *connectedAccountResourceModel) bool {
+	return !plan.Name.Equal(state.Name) ||
+		!plan.Description.Equal(state.Description) ||
+		!plan.ConnectedSecurityServices.Equal(state.ConnectedSecurityServices)
+}
+
 // Configure prepares the provider for data source operations.
 func (r *connectedAccountResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
@@ -113,6 +413,7 @@ func (r *connectedAccountResource) Configure(ctx context.Context, req resource.C
 		return
 	}
 	r.cam = clients.visiononeClients.Cam
+	r.alicloud = clients.alicloudClients
 }
 
 // Create creates the resource and sets the initial state.
@@ -125,6 +426,14 @@ func (r *connectedAccountResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
+	// Catch the typos that would leave the connection in a failed state in VisionOne
+	if plan.VerifyAliCloudTrust.ValueBool() {
+		resp.Diagnostics.Append(r.verifyAliCloudTrust(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	createConnectionReq := &common.CreateConnectionRequest{
 		AccountId:      plan.AccountId.ValueStringPointer(),
 		Region:         plan.StackStateRegion.ValueStringPointer(),
@@ -133,36 +442,54 @@ func (r *connectedAccountResource) Create(ctx context.Context, req resource.Crea
 		Name:           plan.Name.ValueStringPointer(),
 		Description:    plan.Description.ValueStringPointer(),
 	}
+	// An empty set is sent to disable all the services, VisionOne enables its defaults otherwise
+	if !plan.ConnectedSecurityServices.IsNull() && !plan.ConnectedSecurityServices.IsUnknown() {
+		connectedSecurityServices, diags := expandConnectedSecurityServices(ctx, plan.ConnectedSecurityServices)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		createConnectionReq.ConnectedSecurityServices = &connectedSecurityServices
+	}
 	err := r.cam.CreateConnection(ctx, createConnectionReq)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Create Connection Error",
-			"Failed to create connection: "+err.Error(),
-		)
+	if common.IsCamAPIErrorAlreadyExists(err) && plan.AdoptExisting.ValueBool() {
+		// A previous create may have been accepted by VisionOne before it failed in Terraform
+		resp.Diagnostics.Append(r.adoptExistingConnection(ctx, &plan)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	} else if err != nil {
+		addCamErrorDiagnostic(&resp.Diagnostics, "Create Connection Error", "Failed to create connection", err)
 		return
 	}
 
-	readConnectionResp := &common.ReadConnectionResponse{}
-	readConnectionResp, err = r.cam.ReadConnection(ctx, plan.AccountId.ValueStringPointer())
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Read Connection Error",
-			"Failed to read connection: "+err.Error(),
-		)
+	// Wait for VisionOne to validate the role before storing the connection state
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	if readConnectionResp != nil {
-		// Overwrite the plan with the read response
-		plan.AccountId = types.StringValue(*readConnectionResp.Id)
-		plan.StackStateRegion = types.StringValue(*readConnectionResp.ParentStackRegion)
-		plan.RoleArn = types.StringValue(*readConnectionResp.RoleArn)
-		plan.OidcProviderId = types.StringValue(*readConnectionResp.OidcProviderId)
-		plan.Name = types.StringValue(*readConnectionResp.Name)
-		plan.Description = types.StringValue(*readConnectionResp.Description)
-		plan.ConnectionState = types.StringValue(*readConnectionResp.State)
-		plan.CreatedDateTime = types.StringValue(*readConnectionResp.CreatedDateTime)
-		plan.UpdatedDateTime = types.StringValue(*readConnectionResp.UpdatedDateTime)
+	readConnectionResp, err := r.waitForConnectionState(ctx, plan.AccountId.ValueStringPointer(), createTimeout)
+	if err != nil {
+		addCamErrorDiagnostic(&resp.Diagnostics, "Read Connection Error", "Failed to wait for connection", err)
+		return
 	}
+	// Overwrite the plan with the read response
+	plan.AccountId = types.StringValue(*readConnectionResp.Id)
+	plan.StackStateRegion = types.StringValue(*readConnectionResp.ParentStackRegion)
+	plan.RoleArn = types.StringValue(*readConnectionResp.RoleArn)
+	plan.OidcProviderId = types.StringValue(*readConnectionResp.OidcProviderId)
+	plan.Name = types.StringValue(*readConnectionResp.Name)
+	plan.Description = types.StringValue(*readConnectionResp.Description)
+	securityServices, d := keepConfiguredInstanceIds(ctx, plan.ConnectedSecurityServices, readConnectionResp.ConnectedSecurityServices)
+	resp.Diagnostics.Append(d...)
+	plan.ConnectedSecurityServices, diags = flattenConnectedSecurityServices(ctx, securityServices)
+	resp.Diagnostics.Append(diags...)
+	plan.ConnectionState = types.StringValue(*readConnectionResp.State)
+	plan.CreatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.CreatedDateTime)
+	plan.UpdatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.UpdatedDateTime)
+	plan.LastSyncedDateTime, plan.SyncStale, diags = connectionSyncHealth(readConnectionResp, plan.SyncStaleThreshold)
+	resp.Diagnostics.Append(diags...)
 
 	// Set state to fully populated plan
 	diags = resp.State.Set(ctx, &plan)
@@ -170,6 +497,215 @@ func (r *connectedAccountResource) Create(ctx context.Context, req resource.Crea
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// The connection exists in VisionOne even if it failed, so the state is kept
+	// and Terraform marks the resource as tainted.
+	if common.IsConnectionStateFailed(*readConnectionResp.State) {
+		resp.Diagnostics.AddError(
+			"Connection Failed",
+			fmt.Sprintf("VisionOne failed to connect AliCloud Account %s, the connection state is %q. "+
+				"Verify that the role %s trusts the OIDC provider %s.",
+				plan.AccountId.ValueString(), *readConnectionResp.State, plan.RoleArn.ValueString(), plan.OidcProviderId.ValueString()),
+		)
+		return
+	}
+}
+
+// adoptExistingConnection takes over the connection of an account that is already connected,
+// provided that it uses the planned role and OIDC provider. The name, description and security
+// services of the connection are updated to the planned ones.
+func (r *connectedAccountResource) adoptExistingConnection(ctx context.Context, plan *connectedAccountResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	existing, err := r.cam.ReadConnection(ctx, plan.AccountId.ValueStringPointer())
+	if err != nil {
+		addCamErrorDiagnostic(&diags, "Read Connection Error", "Failed to read the existing connection", err)
+		return diags
+	}
+	if existing == nil {
+		diags.AddError(
+			"Create Connection Error",
+			fmt.Sprintf("VisionOne reported that AliCloud Account %s is already connected, but the connection cannot be read. Retry the apply.",
+				plan.AccountId.ValueString()),
+		)
+		return diags
+	}
+
+	var differences []string
+	for _, attribute := range []struct {
+		name     string
+		existing *string
+		planned  types.String
+	}{
+		{name: "stack_state_region", existing: existing.ParentStackRegion, planned: plan.StackStateRegion},
+		{name: "role_arn", existing: existing.RoleArn, planned: plan.RoleArn},
+		{name: "oidc_provider_id", existing: existing.OidcProviderId, planned: plan.OidcProviderId},
+	} {
+		if tea.StringValue(attribute.existing) != attribute.planned.ValueString() {
+			differences = append(differences, fmt.Sprintf("  %s: %q in VisionOne, %q in the configuration",
+				attribute.name, tea.StringValue(attribute.existing), attribute.planned.ValueString()))
+		}
+	}
+	if len(differences) > 0 {
+		diags.AddError(
+			"Connection Already Exists",
+			fmt.Sprintf("AliCloud Account %s is already connected to VisionOne with a different configuration:\n\n%s\n\n"+
+				"Fix the configuration to adopt the existing connection, or disconnect the account in the VisionOne console first.",
+				plan.AccountId.ValueString(), strings.Join(differences, "\n")),
+		)
+		return diags
+	}
+
+	common.LogInfo(ctx, "Adopting the existing connection of the account", map[string]any{
+		"account_id": plan.AccountId.ValueString(),
+	})
+
+	existingSecurityServices, d := flattenConnectedSecurityServices(ctx, existing.ConnectedSecurityServices)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	nameChanged := plan.Name.ValueString() != tea.StringValue(existing.Name)
+	descriptionChanged := !plan.Description.IsUnknown() && plan.Description.ValueString() != tea.StringValue(existing.Description)
+	securityServicesChanged := !plan.ConnectedSecurityServices.IsNull() && !plan.ConnectedSecurityServices.IsUnknown() &&
+		!plan.ConnectedSecurityServices.Equal(existingSecurityServices)
+	if nameChanged || descriptionChanged || securityServicesChanged {
+		updateConnectionReq := &common.UpdateConnectionRequest{
+			Name:        plan.Name.ValueStringPointer(),
+			Description: existing.Description,
+		}
+		if !plan.Description.IsUnknown() {
+			updateConnectionReq.Description = plan.Description.ValueStringPointer()
+		}
+		if securityServicesChanged {
+			connectedSecurityServices, d := expandConnectedSecurityServices(ctx, plan.ConnectedSecurityServices)
+			diags.Append(d...)
+			if diags.HasError() {
+				return diags
+			}
+			updateConnectionReq.ConnectedSecurityServices = &connectedSecurityServices
+		}
+		if err := r.cam.UpdateConnection(ctx, plan.AccountId.ValueStringPointer(), updateConnectionReq); err != nil {
+			addCamErrorDiagnostic(&diags, "Update Connection Error", "Failed to update the existing connection", err)
+		}
+	}
+	return diags
+}
+
+// verifyAliCloudTrust checks with the RAM API that the credentials of the provider belong to
+// the account, that the role exists, and that its trust policy allows the OIDC provider to
+// assume it.
+func (r *connectedAccountResource) verifyAliCloudTrust(ctx context.Context, plan *connectedAccountResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	alicloud, err := r.alicloud.Build()
+	if err != nil {
+		diags.AddError(
+			"Unable to create AliCloud API client",
+			"verify_alicloud_trust requires AliCloud credentials in the alicloud block of the provider: "+err.Error(),
+		)
+		return diags
+	}
+
+	accountId := plan.AccountId.ValueString()
+	callerAccountId, err := alicloud.CallerAccountId()
+	if err != nil {
+		diags.AddError(
+			"Get Caller Identity Error",
+			"Failed to get the AliCloud Account of the credentials: "+err.Error(),
+		)
+		return diags
+	}
+	if callerAccountId != accountId {
+		diags.AddAttributeError(
+			path.Root("account_id"),
+			"AliCloud Account Mismatch",
+			fmt.Sprintf("The AliCloud credentials of the provider belong to the account %s, not to the account %s. "+
+				"Check account_id, or configure the alicloud block of the provider with credentials of the account.", callerAccountId, accountId),
+		)
+		return diags
+	}
+
+	_, roleName, err := common.ParseRoleArn(plan.RoleArn.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("role_arn"), "Invalid Role ARN", err.Error())
+		return diags
+	}
+	getRoleResp, err := alicloud.Ram.GetRole(&ram.GetRoleRequest{RoleName: tea.String(roleName)})
+	if common.IsAliCloudNotFoundError(err) {
+		diags.AddAttributeError(
+			path.Root("role_arn"),
+			"RAM Role Not Found",
+			fmt.Sprintf("The role %s does not exist in the AliCloud Account %s.", roleName, accountId),
+		)
+		return diags
+	}
+	if err != nil {
+		diags.AddError(
+			"Read Role Error",
+			"Failed to read role "+roleName+": "+err.Error(),
+		)
+		return diags
+	}
+
+	oidcProviderArn := common.BuildOidcProviderArn(accountId, plan.OidcProviderId.ValueString())
+	trustPolicy := tea.StringValue(getRoleResp.Body.Role.AssumeRolePolicyDocument)
+	trusted, err := common.TrustPolicyAllowsFederatedPrincipal(trustPolicy, oidcProviderArn)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("role_arn"),
+			"Invalid Role Trust Policy",
+			fmt.Sprintf("Failed to read the trust policy of the role %s: %s", roleName, err.Error()),
+		)
+		return diags
+	}
+	if !trusted {
+		diags.AddAttributeError(
+			path.Root("oidc_provider_id"),
+			"OIDC Provider Not Trusted",
+			fmt.Sprintf("The trust policy of the role %s does not allow the OIDC provider %s to assume it. "+
+				"Check oidc_provider_id, or add an sts:AssumeRole statement for the federated principal %s to the trust policy.",
+				roleName, plan.OidcProviderId.ValueString(), oidcProviderArn),
+		)
+	}
+	return diags
+}
+
+// waitForConnectionState polls the connection until it reaches a healthy or failed state.
+func (r *connectedAccountResource) waitForConnectionState(ctx context.Context, accountId *string, timeout time.Duration) (*common.ReadConnectionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastState := ""
+	for {
+		readConnectionResp, err := r.cam.ReadConnection(ctx, accountId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("timed out after %s waiting for the connection state, last state: %q", timeout, lastState)
+			}
+			return nil, err
+		}
+		if readConnectionResp != nil {
+			lastState = *readConnectionResp.State
+			if common.IsConnectionStateHealthy(lastState) || common.IsConnectionStateFailed(lastState) {
+				return readConnectionResp, nil
+			}
+		}
+
+		common.LogDebug(ctx, "Waiting for connection to reach a terminal state", map[string]any{
+			"account_id":       *accountId,
+			"connection_state": lastState,
+		})
+
+		timer := time.NewTimer(connectionStatePollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("timed out after %s waiting for the connection state, last state: %q", timeout, lastState)
+		case <-timer.C:
+		}
+	}
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -185,17 +721,16 @@ func (r *connectedAccountResource) Read(ctx context.Context, req resource.ReadRe
 	// Get refreshed data from the API
 	readConnectionResp, err := r.cam.ReadConnection(ctx, state.AccountId.ValueStringPointer())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Read Connection Error",
-			"Failed to read connection: "+err.Error(),
-		)
+		addCamErrorDiagnostic(&resp.Diagnostics, "Read Connection Error", "Failed to read connection", err)
 		return
 	}
 	if readConnectionResp == nil {
-		resp.Diagnostics.AddError(
-			"Read Connection Error",
-			"Failed to read connection: response is nil",
-		)
+		// The account was disconnected outside of Terraform, remove it from the state
+		// so that Terraform plans to recreate it.
+		common.LogWarn(ctx, "Connected account not found, removing from state", map[string]any{
+			"account_id": state.AccountId.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
 		return
 	} else {
 		// Overwrite the state with the read response
@@ -205,9 +740,15 @@ func (r *connectedAccountResource) Read(ctx context.Context, req resource.ReadRe
 		state.OidcProviderId = types.StringValue(*readConnectionResp.OidcProviderId)
 		state.Name = types.StringValue(*readConnectionResp.Name)
 		state.Description = types.StringValue(*readConnectionResp.Description)
+		securityServices, d := keepConfiguredInstanceIds(ctx, state.ConnectedSecurityServices, readConnectionResp.ConnectedSecurityServices)
+		resp.Diagnostics.Append(d...)
+		state.ConnectedSecurityServices, diags = flattenConnectedSecurityServices(ctx, securityServices)
+		resp.Diagnostics.Append(diags...)
 		state.ConnectionState = types.StringValue(*readConnectionResp.State)
-		state.CreatedDateTime = types.StringValue(*readConnectionResp.CreatedDateTime)
-		state.UpdatedDateTime = types.StringValue(*readConnectionResp.UpdatedDateTime)
+		state.CreatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.CreatedDateTime)
+		state.UpdatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.UpdatedDateTime)
+		state.LastSyncedDateTime, state.SyncStale, diags = connectionSyncHealth(readConnectionResp, state.SyncStaleThreshold)
+		resp.Diagnostics.Append(diags...)
 	}
 
 	// Set refreshed state
@@ -220,35 +761,44 @@ func (r *connectedAccountResource) Read(ctx context.Context, req resource.ReadRe
 
 // Update modifies the existing resource and sets the updated state.
 func (r *connectedAccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve the values from plan
-	var plan connectedAccountResourceModel
+	// Retrieve the values from plan and state
+	var plan, state connectedAccountResourceModel
 	diags := req.Plan.Get(ctx, &plan)
-	if diags.HasError() {
-		resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Update the connection
-	updateConnectionReq := &common.UpdateConnectionRequest{
-		Name:        plan.Name.ValueStringPointer(),
-		Description: plan.Description.ValueStringPointer(),
-	}
-	err := r.cam.UpdateConnection(ctx, plan.AccountId.ValueStringPointer(), updateConnectionReq)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Update Connection Error",
-			"Failed to update connection: "+err.Error(),
-		)
-		return
+	// Update the connection, unless only the attributes used by the provider changed
+	if connectionChanged(&plan, &state) {
+		updateConnectionReq := &common.UpdateConnectionRequest{
+			Name:        plan.Name.ValueStringPointer(),
+			Description: plan.Description.ValueStringPointer(),
+		}
+		if !plan.ConnectedSecurityServices.IsNull() && !plan.ConnectedSecurityServices.IsUnknown() {
+			connectedSecurityServices, diags := expandConnectedSecurityServices(ctx, plan.ConnectedSecurityServices)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			updateConnectionReq.ConnectedSecurityServices = &connectedSecurityServices
+		}
+		err := r.cam.UpdateConnection(ctx, plan.AccountId.ValueStringPointer(), updateConnectionReq)
+		if err != nil {
+			addCamErrorDiagnostic(&resp.Diagnostics, "Update Connection Error", "Failed to update connection", err)
+			return
+		}
+	} else {
+		common.LogDebug(ctx, "Skipping the update of the connection, only provider attributes changed", map[string]any{
+			"account_id": plan.AccountId.ValueString(),
+		})
 	}
 
 	// Read the updated connection
 	readConnectionResp, err := r.cam.ReadConnection(ctx, plan.AccountId.ValueStringPointer())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Read Connection Error",
-			"Failed to read connection: "+err.Error(),
-		)
+		addCamErrorDiagnostic(&resp.Diagnostics, "Read Connection Error", "Failed to read connection", err)
 		return
 	}
 	if readConnectionResp == nil {
@@ -265,9 +815,15 @@ func (r *connectedAccountResource) Update(ctx context.Context, req resource.Upda
 		plan.OidcProviderId = types.StringValue(*readConnectionResp.OidcProviderId)
 		plan.Name = types.StringValue(*readConnectionResp.Name)
 		plan.Description = types.StringValue(*readConnectionResp.Description)
+		securityServices, d := keepConfiguredInstanceIds(ctx, plan.ConnectedSecurityServices, readConnectionResp.ConnectedSecurityServices)
+		resp.Diagnostics.Append(d...)
+		plan.ConnectedSecurityServices, diags = flattenConnectedSecurityServices(ctx, securityServices)
+		resp.Diagnostics.Append(diags...)
 		plan.ConnectionState = types.StringValue(*readConnectionResp.State)
-		plan.CreatedDateTime = types.StringValue(*readConnectionResp.CreatedDateTime)
-		plan.UpdatedDateTime = types.StringValue(*readConnectionResp.UpdatedDateTime)
+		plan.CreatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.CreatedDateTime)
+		plan.UpdatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.UpdatedDateTime)
+		plan.LastSyncedDateTime, plan.SyncStale, diags = connectionSyncHealth(readConnectionResp, plan.SyncStaleThreshold)
+		resp.Diagnostics.Append(diags...)
 	}
 
 	// Set state to fully populated plan
@@ -291,10 +847,7 @@ func (r *connectedAccountResource) Delete(ctx context.Context, req resource.Dele
 	// Delete the connection
 	err := r.cam.DeleteConnection(ctx, state.AccountId.ValueStringPointer())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Delete Connection Error",
-			"Failed to delete connection: "+err.Error(),
-		)
+		addCamErrorDiagnostic(&resp.Diagnostics, "Delete Connection Error", "Failed to delete connection", err)
 		return
 	}
 
@@ -305,7 +858,62 @@ func (r *connectedAccountResource) Delete(ctx context.Context, req resource.Dele
 	}
 }
 
-// ImportState imports the resource state.
+// ImportState imports the resource state by the ID of the AliCloud Account.
 func (r *connectedAccountResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	panic("not implemented")
+	if req.ID == "" {
+		resp.Diagnostics.AddError(
+			"Import Connection Error",
+			"The import ID must be the ID of the connected AliCloud Account.",
+		)
+		return
+	}
+
+	// Read the connection from the API
+	readConnectionResp, err := r.cam.ReadConnection(ctx, &req.ID)
+	if err != nil {
+		addCamErrorDiagnostic(&resp.Diagnostics, "Import Connection Error", "Failed to read connection", err)
+		return
+	}
+	if readConnectionResp == nil {
+		resp.Diagnostics.AddError(
+			"Import Connection Error",
+			fmt.Sprintf("AliCloud Account %s is not connected to VisionOne Cloud Account Management.", req.ID),
+		)
+		return
+	}
+
+	state := connectedAccountResourceModel{
+		AccountId:        types.StringValue(*readConnectionResp.Id),
+		StackStateRegion: types.StringValue(*readConnectionResp.ParentStackRegion),
+		RoleArn:          types.StringValue(*readConnectionResp.RoleArn),
+		OidcProviderId:   types.StringValue(*readConnectionResp.OidcProviderId),
+		Name:             types.StringValue(*readConnectionResp.Name),
+		Description:      types.StringValue(*readConnectionResp.Description),
+		ConnectionState:  types.StringValue(*readConnectionResp.State),
+		CreatedDateTime:  timetypes.NewRFC3339TimePointerValue(readConnectionResp.CreatedDateTime),
+		UpdatedDateTime:  timetypes.NewRFC3339TimePointerValue(readConnectionResp.UpdatedDateTime),
+		Timeouts:         nullConnectedAccountTimeouts(),
+
+		VerifyAliCloudTrust: types.BoolNull(),
+		AdoptExisting:       types.BoolNull(),
+		SyncStaleThreshold:  types.StringNull(),
+	}
+	connectedSecurityServices, diags := flattenConnectedSecurityServices(ctx, readConnectionResp.ConnectedSecurityServices)
+	resp.Diagnostics.Append(diags...)
+	state.ConnectedSecurityServices = connectedSecurityServices
+	lastSyncedDateTime, syncStale, diags := connectionSyncHealth(readConnectionResp, state.SyncStaleThreshold)
+	resp.Diagnostics.Append(diags...)
+	state.LastSyncedDateTime = lastSyncedDateTime
+	state.SyncStale = syncStale
+	// The API may omit the ID in the response body, fall back to the import ID
+	if state.AccountId.ValueString() == "" {
+		state.AccountId = types.StringValue(req.ID)
+	}
+
+	// Set the imported state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 }
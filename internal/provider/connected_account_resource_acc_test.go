@@ -0,0 +1,682 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"terraform-provider-alicloudsecurity/internal/common"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+// testAccConnectedAccountConfig returns the configuration of a connected account.
+func testAccConnectedAccountConfig(name, description string) string {
+	return fmt.Sprintf(`
+resource "alicloudsecurity_connected_account" "test" {
+  stack_state_region = "us-east-1"
+  account_id         = %q
+  role_arn           = "acs:ram::%s:role/visionone"
+  oidc_provider_id   = "trendmicro-visionone"
+  name               = %q
+  description        = %q
+}
+`, fakeStsAccountId, fakeStsAccountId, name, description)
+}
+
+// testAccCheckConnectedAccountDestroyed checks that the connected accounts of the state are
+// disconnected from the fake CAM server.
+func testAccCheckConnectedAccountDestroyed(cam *fakeCamServer) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "alicloudsecurity_connected_account" {
+				continue
+			}
+			if account := cam.Account(rs.Primary.Attributes["account_id"]); account != nil {
+				return fmt.Errorf("account %s is still connected", *account.Id)
+			}
+		}
+		return nil
+	}
+}
+
+// setTestConnectionStatePollInterval shortens the poll interval of the connection state for
+// the duration of the test.
+func setTestConnectionStatePollInterval(t *testing.T) {
+	pollInterval := connectionStatePollInterval
+	connectionStatePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { connectionStatePollInterval = pollInterval })
+}
+
+func TestAccConnectedAccountResource(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	for endpointType := range fakeCamPathPrefixes {
+		t.Run(endpointType, func(t *testing.T) {
+			cam := newFakeCamServer(t)
+			cam.CreateStates = []string{"", "", common.ConnectionStateManaged}
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+				Steps: []resource.TestStep{
+					// Create and Read testing
+					{
+						Config: cam.ProviderConfig(endpointType) + testAccConnectedAccountConfig("test", "created"),
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "account_id", fakeStsAccountId),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "name", "test"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "description", "created"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connection_state", common.ConnectionStateManaged),
+							resource.TestCheckResourceAttrSet("alicloudsecurity_connected_account.test", "created_date_time"),
+							resource.TestCheckResourceAttrSet("alicloudsecurity_connected_account.test", "last_synced_date_time"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "sync_stale", "false"),
+						),
+					},
+					// ImportState testing
+					{
+						ResourceName:                         "alicloudsecurity_connected_account.test",
+						ImportState:                          true,
+						ImportStateId:                        fakeStsAccountId,
+						ImportStateVerify:                    true,
+						ImportStateVerifyIdentifierAttribute: "account_id",
+						ImportStateVerifyIgnore:              []string{"timeouts", "updated_date_time"},
+					},
+					// Update and Read testing
+					{
+						Config: cam.ProviderConfig(endpointType) + testAccConnectedAccountConfig("test-updated", "updated"),
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "name", "test-updated"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "description", "updated"),
+						),
+					},
+					// Recreate an account disconnected outside of Terraform
+					{
+						PreConfig: func() {
+							accountId := fakeStsAccountId
+							if err := newTestFakeCamClient(t, cam, endpointType).DeleteConnection(context.Background(), &accountId); err != nil {
+								t.Fatalf("failed to disconnect account: %v", err)
+							}
+						},
+						Config: cam.ProviderConfig(endpointType) + testAccConnectedAccountConfig("test-updated", "updated"),
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connection_state", common.ConnectionStateManaged),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAccConnectedAccountResourceFailedState(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	cam := newFakeCamServer(t)
+	cam.CreateStates = []string{"", common.ConnectionStateFailed}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config:      cam.ProviderConfig("automation") + testAccConnectedAccountConfig("test", ""),
+				ExpectError: regexp.MustCompile(`Connection Failed`),
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountResourceCreateError(t *testing.T) {
+	cam := newFakeCamServer(t)
+	cam.InjectFault(http.MethodPost, http.StatusForbidden, "AccessDenied")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config:      cam.ProviderConfig("automation") + testAccConnectedAccountConfig("test", ""),
+				ExpectError: regexp.MustCompile(`AccessDenied`),
+			},
+		},
+	})
+}
+
+// testAccConnectedAccountRoleConfig returns the configuration of a connected account with the
+// given account and role.
+func testAccConnectedAccountRoleConfig(accountId, roleName, name string) string {
+	return fmt.Sprintf(`
+resource "alicloudsecurity_connected_account" "test" {
+  stack_state_region = "us-east-1"
+  account_id         = %q
+  role_arn           = "acs:ram::%s:role/%s"
+  oidc_provider_id   = "trendmicro-visionone"
+  name               = %q
+}
+`, accountId, accountId, roleName, name)
+}
+
+func TestAccConnectedAccountResourceReplace(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	cam := newFakeCamServer(t)
+	otherAccountId := "6543210987654321"
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountRoleConfig(fakeStsAccountId, "visionone", "test"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "description", ""),
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connection_state", common.ConnectionStateManaged),
+				),
+			},
+			// A new name updates the connection in place
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountRoleConfig(fakeStsAccountId, "visionone", "renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("alicloudsecurity_connected_account.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("alicloudsecurity_connected_account.test", tfjsonpath.New("connection_state"),
+							knownvalue.StringExact(common.ConnectionStateManaged)),
+						plancheck.ExpectKnownValue("alicloudsecurity_connected_account.test", tfjsonpath.New("description"),
+							knownvalue.StringExact("")),
+						plancheck.ExpectKnownValue("alicloudsecurity_connected_account.test", tfjsonpath.New("created_date_time"),
+							knownvalue.NotNull()),
+						plancheck.ExpectUnknownValue("alicloudsecurity_connected_account.test", tfjsonpath.New("updated_date_time")),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "name", "renamed"),
+			},
+			// A new role replaces the connection
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountRoleConfig(fakeStsAccountId, "visionone-renamed", "renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("alicloudsecurity_connected_account.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "role_arn",
+					"acs:ram::"+fakeStsAccountId+":role/visionone-renamed"),
+			},
+			// A new account replaces the connection
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountRoleConfig(otherAccountId, "visionone-renamed", "renamed"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("alicloudsecurity_connected_account.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: func(*terraform.State) error {
+					if cam.Account(fakeStsAccountId) != nil {
+						return fmt.Errorf("expected account %s to be disconnected", fakeStsAccountId)
+					}
+					if cam.Account(otherAccountId) == nil {
+						return fmt.Errorf("expected account %s to be connected", otherAccountId)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountResourceInvalidConfig(t *testing.T) {
+	cam := newFakeCamServer(t)
+
+	tests := map[string]struct {
+		accountId          string
+		roleArn            string
+		region             string
+		syncStaleThreshold string
+		expectError        *regexp.Regexp
+	}{
+		"account id not numeric": {
+			accountId:   "account",
+			roleArn:     "acs:ram::1234567890123456:role/visionone",
+			region:      "us-east-1",
+			expectError: regexp.MustCompile(`must only contain digits`),
+		},
+		"account id too long": {
+			accountId:   "12345678901234567",
+			roleArn:     "acs:ram::1234567890123456:role/visionone",
+			region:      "us-east-1",
+			expectError: regexp.MustCompile(`string length must be between 1 and 16`),
+		},
+		"role arn malformed": {
+			accountId:   fakeStsAccountId,
+			roleArn:     "arn:aws:iam::123456789012:role/visionone",
+			region:      "us-east-1",
+			expectError: regexp.MustCompile(`must be a RAM role ARN`),
+		},
+		"role arn of another account": {
+			accountId:   fakeStsAccountId,
+			roleArn:     "acs:ram::6543210987654321:role/visionone",
+			region:      "us-east-1",
+			expectError: regexp.MustCompile(`Role ARN Account Mismatch`),
+		},
+		"unknown region": {
+			accountId:   fakeStsAccountId,
+			roleArn:     "acs:ram::1234567890123456:role/visionone",
+			region:      "us-east-2",
+			expectError: regexp.MustCompile(`value must be one of`),
+		},
+		"sync stale threshold not a duration": {
+			accountId:          fakeStsAccountId,
+			roleArn:            "acs:ram::1234567890123456:role/visionone",
+			region:             "us-east-1",
+			syncStaleThreshold: "1 day",
+			expectError:        regexp.MustCompile(`must be a positive duration`),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			syncStaleThreshold := "null"
+			if tt.syncStaleThreshold != "" {
+				syncStaleThreshold = fmt.Sprintf("%q", tt.syncStaleThreshold)
+			}
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: cam.ProviderConfig("automation") + fmt.Sprintf(`
+resource "alicloudsecurity_connected_account" "test" {
+  stack_state_region   = %q
+  account_id           = %q
+  role_arn             = %q
+  oidc_provider_id     = "trendmicro-visionone"
+  name                 = "test"
+  sync_stale_threshold = %s
+}
+`, tt.region, tt.accountId, tt.roleArn, syncStaleThreshold),
+						PlanOnly:    true,
+						ExpectError: tt.expectError,
+					},
+				},
+			})
+		})
+	}
+
+	if requests := cam.Requests(); len(requests) != 0 {
+		t.Errorf("expected no request to VisionOne, got %v", requests)
+	}
+}
+
+func TestAccConnectedAccountResourceVerifyAliCloudTrust(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	trustPolicy, err := common.BuildVisionOneTrustPolicy(fakeStsAccountId, "trendmicro-visionone", fakeCamBusinessId, fakeCamRegion)
+	if err != nil {
+		t.Fatalf("failed to build trust policy: %v", err)
+	}
+	otherTrustPolicy, err := common.BuildVisionOneTrustPolicy(fakeStsAccountId, "other-oidc-provider", fakeCamBusinessId, fakeCamRegion)
+	if err != nil {
+		t.Fatalf("failed to build trust policy: %v", err)
+	}
+
+	tests := map[string]struct {
+		callerAccountId string
+		roles           map[string]string
+		expectError     *regexp.Regexp
+	}{
+		"trusted": {
+			callerAccountId: fakeStsAccountId,
+			roles:           map[string]string{"visionone": trustPolicy},
+		},
+		"account mismatch": {
+			callerAccountId: "6543210987654321",
+			roles:           map[string]string{"visionone": trustPolicy},
+			expectError:     regexp.MustCompile(`AliCloud Account Mismatch`),
+		},
+		"role not found": {
+			callerAccountId: fakeStsAccountId,
+			roles:           map[string]string{},
+			expectError:     regexp.MustCompile(`RAM Role Not Found`),
+		},
+		"oidc provider not trusted": {
+			callerAccountId: fakeStsAccountId,
+			roles:           map[string]string{"visionone": otherTrustPolicy},
+			expectError:     regexp.MustCompile(`OIDC Provider Not Trusted`),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cam := newFakeCamServer(t)
+			alicloud := newFakeAliCloudServer(t, tt.callerAccountId)
+			for roleName, rolePolicy := range tt.roles {
+				alicloud.PutRole(roleName, rolePolicy)
+			}
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+				Steps: []resource.TestStep{
+					{
+						Config: cam.ProviderConfig("automation", alicloud.ProviderConfig()) + fmt.Sprintf(`
+resource "alicloudsecurity_connected_account" "test" {
+  stack_state_region    = "us-east-1"
+  account_id            = %q
+  role_arn              = "acs:ram::%s:role/visionone"
+  oidc_provider_id      = "trendmicro-visionone"
+  name                  = "test"
+  verify_alicloud_trust = true
+}
+`, fakeStsAccountId, fakeStsAccountId),
+						ExpectError: tt.expectError,
+						Check:       resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connection_state", common.ConnectionStateManaged),
+					},
+				},
+			})
+
+			if tt.expectError != nil {
+				for _, request := range cam.Requests() {
+					if strings.HasPrefix(request, http.MethodPost) {
+						t.Errorf("expected no connection to be created, got %s", request)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAccConnectedAccountResourceAdoptExisting(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	tests := map[string]struct {
+		existingRoleArn string
+		existingRegion  string
+		adoptExisting   string
+		expectError     *regexp.Regexp
+	}{
+		"matching connection": {
+			existingRoleArn: "acs:ram::" + fakeStsAccountId + ":role/visionone",
+			adoptExisting:   "true",
+		},
+		"role mismatch": {
+			existingRoleArn: "acs:ram::" + fakeStsAccountId + ":role/other",
+			adoptExisting:   "true",
+			expectError:     regexp.MustCompile(`(?s)Connection Already Exists.*role_arn: "acs:ram::1234567890123456:role/other"`),
+		},
+		"region mismatch": {
+			existingRoleArn: "acs:ram::" + fakeStsAccountId + ":role/visionone",
+			existingRegion:  "ap-southeast-1",
+			adoptExisting:   "true",
+			expectError:     regexp.MustCompile(`(?s)Connection Already Exists.*stack_state_region: "ap-southeast-1"`),
+		},
+		"adoption by default": {
+			existingRoleArn: "acs:ram::" + fakeStsAccountId + ":role/visionone",
+			expectError:     regexp.MustCompile(`adopt_existing = true`),
+		},
+		"adoption disabled": {
+			existingRoleArn: "acs:ram::" + fakeStsAccountId + ":role/visionone",
+			adoptExisting:   "false",
+			expectError:     regexp.MustCompile(`already connected`),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cam := newFakeCamServer(t)
+			existingRegion := "us-east-1"
+			if tt.existingRegion != "" {
+				existingRegion = tt.existingRegion
+			}
+			err := newTestFakeCamClient(t, cam, "automation").CreateConnection(context.Background(), &common.CreateConnectionRequest{
+				AccountId:      tea.String(fakeStsAccountId),
+				Region:         tea.String(existingRegion),
+				RoleArn:        tea.String(tt.existingRoleArn),
+				OidcProviderId: tea.String("trendmicro-visionone"),
+				Name:           tea.String("previous"),
+				Description:    tea.String("previous"),
+			})
+			if err != nil {
+				t.Fatalf("failed to connect account: %v", err)
+			}
+
+			adoptExisting := ""
+			if tt.adoptExisting != "" {
+				adoptExisting = "  adopt_existing     = " + tt.adoptExisting + "\n"
+			}
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+				Steps: []resource.TestStep{
+					{
+						Config: cam.ProviderConfig("automation") + strings.Replace(testAccConnectedAccountConfig("test", "created"),
+							"  name ", adoptExisting+"  name ", 1),
+						ExpectError: tt.expectError,
+						Check: resource.ComposeAggregateTestCheckFunc(
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "name", "test"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "description", "created"),
+							resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connection_state", common.ConnectionStateManaged),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestAccConnectedAccountResourceSecurityServices(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+
+	config := func(connectedSecurityServices string) string {
+		return cam.ProviderConfig("automation") + strings.Replace(testAccConnectedAccountConfig("test", "services"),
+			"  name ", "  connected_security_services = "+connectedSecurityServices+"\n  name ", 1)
+	}
+	checkServices := func(expected ...string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			account := cam.Account(fakeStsAccountId)
+			if account == nil {
+				return fmt.Errorf("account %s is not connected", fakeStsAccountId)
+			}
+			var names []string
+			for _, service := range account.ConnectedSecurityServices {
+				names = append(names, tea.StringValue(service.Name))
+			}
+			sort.Strings(names)
+			sort.Strings(expected)
+			if strings.Join(names, ",") != strings.Join(expected, ",") {
+				return fmt.Errorf("expected the security services %v in VisionOne, got %v", expected, names)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			// Create with the services of VisionOne
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountConfig("test", "services"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connected_security_services.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("alicloudsecurity_connected_account.test", "connected_security_services.*", map[string]string{
+						"name": "cloud-posture",
+					}),
+					checkServices("cloud-posture"),
+				),
+			},
+			// Enable services
+			{
+				Config: config(`[
+    { name = "cloud-posture" },
+    { name = "agentless-vulnerability-threat-detection", instance_ids = ["instance-1", "instance-2"] },
+  ]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connected_security_services.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("alicloudsecurity_connected_account.test", "connected_security_services.*", map[string]string{
+						"name":           "agentless-vulnerability-threat-detection",
+						"instance_ids.#": "2",
+					}),
+					resource.TestCheckTypeSetElemAttr("alicloudsecurity_connected_account.test", "connected_security_services.*.instance_ids.*", "instance-1"),
+					resource.TestCheckTypeSetElemAttr("alicloudsecurity_connected_account.test", "connected_security_services.*.instance_ids.*", "instance-2"),
+					resource.TestCheckTypeSetElemNestedAttrs("alicloudsecurity_connected_account.test", "connected_security_services.*", map[string]string{
+						"name": "cloud-posture",
+					}),
+					checkServices("cloud-posture", "agentless-vulnerability-threat-detection"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "alicloudsecurity_connected_account.test",
+				ImportState:                          true,
+				ImportStateId:                        fakeStsAccountId,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "account_id",
+				ImportStateVerifyIgnore:              []string{"timeouts", "updated_date_time"},
+			},
+			// Detect the services changed outside of Terraform
+			{
+				PreConfig: func() {
+					accountId := fakeStsAccountId
+					err := newTestFakeCamClient(t, cam, "automation").UpdateConnection(context.Background(), &accountId, &common.UpdateConnectionRequest{
+						Name:                      tea.String("test"),
+						Description:               tea.String("services"),
+						ConnectedSecurityServices: &[]common.ConnectedSecurityService{{Name: tea.String("cloud-posture")}},
+					})
+					if err != nil {
+						t.Fatalf("failed to update connection: %v", err)
+					}
+				},
+				Config: config(`[
+    { name = "cloud-posture" },
+    { name = "agentless-vulnerability-threat-detection", instance_ids = ["instance-1", "instance-2"] },
+  ]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			// Disable all the services
+			{
+				Config: config("[]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connected_security_services.#", "0"),
+					checkServices(),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountResourceSecurityServicesDisabledAtCreate(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			// The empty set disables the services that VisionOne enables by default
+			{
+				Config: cam.ProviderConfig("automation") + strings.Replace(testAccConnectedAccountConfig("test", "services"),
+					"  name ", "  connected_security_services = []\n  name ", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_connected_account.test", "connected_security_services.#", "0"),
+					func(*terraform.State) error {
+						account := cam.Account(fakeStsAccountId)
+						if account == nil {
+							return fmt.Errorf("account %s is not connected", fakeStsAccountId)
+						}
+						if len(account.ConnectedSecurityServices) != 0 {
+							return fmt.Errorf("expected no security services in VisionOne, got %d", len(account.ConnectedSecurityServices))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountResourceExtraInstanceIds(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+	cam.ExtraInstanceIds = map[string][]string{
+		"cloud-posture": {"posture-1"},
+		"agentless-vulnerability-threat-detection": {"instance-visionone"},
+	}
+
+	config := cam.ProviderConfig("automation") + strings.Replace(testAccConnectedAccountConfig("test", "services"),
+		"  name ", `  connected_security_services = [
+    { name = "cloud-posture" },
+    { name = "agentless-vulnerability-threat-detection", instance_ids = ["instance-1"] },
+  ]
+  name `, 1)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			// The instances of VisionOne are reported for the services without instance_ids only
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("alicloudsecurity_connected_account.test", "connected_security_services.*", map[string]string{
+						"name":           "cloud-posture",
+						"instance_ids.#": "1",
+						"instance_ids.0": "posture-1",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs("alicloudsecurity_connected_account.test", "connected_security_services.*", map[string]string{
+						"name":           "agentless-vulnerability-threat-detection",
+						"instance_ids.#": "1",
+						"instance_ids.0": "instance-1",
+					}),
+				),
+			},
+			// The refreshed state keeps matching the configuration
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountResourceTimestampFormat(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+	utcTimestamp := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountConfig("test", "timestamps"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("alicloudsecurity_connected_account.test", "created_date_time", utcTimestamp),
+					resource.TestMatchResourceAttr("alicloudsecurity_connected_account.test", "last_synced_date_time", utcTimestamp),
+				),
+			},
+			// The same instants in another time zone are not a change
+			{
+				PreConfig: func() {
+					cam.SetTimeZone(fakeStsAccountId, time.FixedZone("UTC+8", 8*60*60))
+				},
+				Config: cam.ProviderConfig("automation") + testAccConnectedAccountConfig("test", "timestamps"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("alicloudsecurity_connected_account.test", "created_date_time", utcTimestamp),
+					resource.TestMatchResourceAttr("alicloudsecurity_connected_account.test", "updated_date_time", utcTimestamp),
+					resource.TestMatchResourceAttr("alicloudsecurity_connected_account.test", "last_synced_date_time", utcTimestamp),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,436 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"terraform-provider-alicloudsecurity/internal/common"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// newTestConnectedAccountResource returns a connected account resource whose CAM client
+// sends requests to the given test server.
+func newTestConnectedAccountResource(t *testing.T, server *httptest.Server) *connectedAccountResource {
+	t.Helper()
+
+	clients := &common.VisionOneClients{}
+	cam, err := clients.BuildCamClient(context.Background(), server.URL, "automation", "test-business-id", "test-api-key", "us")
+	if err != nil {
+		t.Fatalf("failed to create CAM client: %v", err)
+	}
+	return &connectedAccountResource{cam: cam}
+}
+
+// newTestConnectedAccountState returns a state holding a connected account.
+func newTestConnectedAccountState(t *testing.T, r *connectedAccountResource) tfsdk.State {
+	t.Helper()
+
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	diags := state.Set(ctx, &connectedAccountResourceModel{
+		StackStateRegion: types.StringValue("us-east-1"),
+		AccountId:        types.StringValue("1234567890"),
+		RoleArn:          types.StringValue("acs:ram::1234567890:role/visionone"),
+		OidcProviderId:   types.StringValue("visionone-oidc"),
+		Name:             types.StringValue("test"),
+		Description:      types.StringValue(""),
+		ConnectionState:  types.StringValue("managed"),
+		CreatedDateTime:  timetypes.NewRFC3339ValueMust("2025-01-01T00:00:00Z"),
+		UpdatedDateTime:  timetypes.NewRFC3339ValueMust("2025-01-01T00:00:00Z"),
+		Timeouts:         nullConnectedAccountTimeouts(),
+
+		ConnectedSecurityServices: types.SetNull(types.ObjectType{AttrTypes: connectedSecurityServiceAttributeTypes}),
+	})
+	if diags.HasError() {
+		t.Fatalf("failed to set state: %v", diags)
+	}
+	return state
+}
+
+func TestConnectedAccountResourceReadRemovesDisconnectedAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	state := newTestConnectedAccountState(t, r)
+
+	resp := &resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Fatal("expected the resource to be removed from state")
+	}
+}
+
+func TestConnectedAccountResourceDeleteDisconnectedAccount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	state := newTestConnectedAccountState(t, r)
+
+	resp := &resource.DeleteResponse{State: state}
+	r.Delete(context.Background(), resource.DeleteRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Fatal("expected the resource to be removed from state")
+	}
+}
+
+// newTestConnectedAccountImportResponse returns an import response with an empty state.
+func newTestConnectedAccountImportResponse(t *testing.T, r *connectedAccountResource) *resource.ImportStateResponse {
+	t.Helper()
+
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	return &resource.ImportStateResponse{State: tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}}
+}
+
+func TestConnectedAccountResourceImportState(t *testing.T) {
+	server, _ := newTestConnectionStateServer(t, common.ConnectionStateManaged)
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	resp := newTestConnectedAccountImportResponse(t, r)
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: "1234567890"}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state connectedAccountResourceModel
+	resp.State.Get(context.Background(), &state)
+	if state.AccountId.ValueString() != "1234567890" || state.RoleArn.ValueString() != "acs:ram::1234567890:role/visionone" {
+		t.Errorf("unexpected imported account %s with role %s", state.AccountId, state.RoleArn)
+	}
+	if state.ConnectionState.ValueString() != common.ConnectionStateManaged {
+		t.Errorf("expected connection state %q, got %q", common.ConnectionStateManaged, state.ConnectionState.ValueString())
+	}
+}
+
+func TestConnectedAccountResourceImportStateNotConnected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	resp := newTestConnectedAccountImportResponse(t, r)
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: "1234567890"}, resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error diagnostic")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "is not connected") {
+		t.Fatalf("expected the diagnostic to report the account as not connected, got %q", detail)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Fatal("expected no imported state")
+	}
+}
+
+// newTestConnectedAccountPlan returns a plan to create a connected account.
+func newTestConnectedAccountPlan(t *testing.T, r *connectedAccountResource) tfsdk.Plan {
+	t.Helper()
+
+	state := newTestConnectedAccountState(t, r)
+	plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+	for _, attribute := range []string{"connection_state", "created_date_time", "updated_date_time"} {
+		diags := plan.SetAttribute(context.Background(), path.Root(attribute), types.StringUnknown())
+		if diags.HasError() {
+			t.Fatalf("failed to set plan: %v", diags)
+		}
+	}
+	return plan
+}
+
+// newTestConnectionStateServer returns a test server that accepts the connection and then
+// reports the given states on consecutive reads, repeating the last one.
+func newTestConnectionStateServer(t *testing.T, states ...string) (*httptest.Server, *int) {
+	t.Helper()
+
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			state := states[min(reads, len(states)-1)]
+			reads++
+			_, _ = fmt.Fprintf(w, `{"id":"1234567890","parentStackRegion":"us-east-1","roleArn":"acs:ram::1234567890:role/visionone",`+
+				`"oidcProviderId":"visionone-oidc","name":"test","description":"","state":%q,`+
+				`"createdDateTime":"2025-01-01T00:00:00Z","updatedDateTime":"2025-01-01T00:00:00Z"}`, state)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return server, &reads
+}
+
+func TestConnectedAccountResourceCreateWaitsForTerminalState(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	server, reads := newTestConnectionStateServer(t, "", "pending", common.ConnectionStateManaged)
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	plan := newTestConnectedAccountPlan(t, r)
+
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if *reads != 3 {
+		t.Fatalf("expected 3 reads, got %d", *reads)
+	}
+
+	var state connectedAccountResourceModel
+	resp.State.Get(context.Background(), &state)
+	if state.ConnectionState.ValueString() != common.ConnectionStateManaged {
+		t.Fatalf("expected connection state %q, got %q", common.ConnectionStateManaged, state.ConnectionState.ValueString())
+	}
+}
+
+func TestConnectedAccountResourceCreateReportsFailedState(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+
+	server, _ := newTestConnectionStateServer(t, "pending", common.ConnectionStateFailed)
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	plan := newTestConnectedAccountPlan(t, r)
+
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error diagnostic")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, common.ConnectionStateFailed) {
+		t.Fatalf("expected the diagnostic to include the connection state, got %q", detail)
+	}
+	// The failed connection is kept in the state so that it can be replaced
+	if resp.State.Raw.IsNull() {
+		t.Fatal("expected the failed connection to be kept in the state")
+	}
+}
+
+func TestConnectedAccountResourceCreateDoesNotAdoptByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":{"code":"AccountAlreadyExists","message":"The account already exists"}}`))
+	}))
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	plan := newTestConnectedAccountPlan(t, r)
+
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error diagnostic")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "adopt_existing = true") {
+		t.Fatalf("expected the diagnostic to point at adopt_existing, got %q", detail)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Fatal("expected no state for an account that was not adopted")
+	}
+}
+
+func TestConnectedAccountResourceCreateDoesNotAdoptAnotherRegion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":{"code":"AccountAlreadyExists","message":"The account already exists"}}`))
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id":"1234567890","parentStackRegion":"ap-southeast-1","roleArn":"acs:ram::1234567890:role/visionone",` +
+				`"oidcProviderId":"visionone-oidc","name":"test","description":"","state":"managed",` +
+				`"createdDateTime":"2025-01-01T00:00:00Z","updatedDateTime":"2025-01-01T00:00:00Z"}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	plan := newTestConnectedAccountPlan(t, r)
+	if diags := plan.SetAttribute(context.Background(), path.Root("adopt_existing"), types.BoolValue(true)); diags.HasError() {
+		t.Fatalf("failed to set plan: %v", diags)
+	}
+
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Connection Already Exists" {
+		t.Fatalf("expected a connection already exists error, got %v", resp.Diagnostics)
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, `stack_state_region: "ap-southeast-1"`) {
+		t.Fatalf("expected the diagnostic to show the region of the existing connection, got %q", detail)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Fatal("expected no state for a connection that was not adopted")
+	}
+}
+
+func TestConnectedAccountResourceRoleArnValidator(t *testing.T) {
+	for roleArn, valid := range map[string]bool{
+		"acs:ram::1234567890123456:role/visionone":            true,
+		"acs:ram::1234567890123456:role/vision/one":           false,
+		"acs:ram:cn-hangzhou:1234567890123456:role/visionone": false,
+		"arn:aws:iam::123456789012:role/visionone":            false,
+	} {
+		resp := &validator.StringResponse{}
+		roleArnValidator{}.ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("role_arn"),
+			ConfigValue: types.StringValue(roleArn),
+		}, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("expected %q to be valid: %t, got diagnostics %v", roleArn, valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestKeepConfiguredInstanceIds(t *testing.T) {
+	ctx := context.Background()
+	configured, diags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: connectedSecurityServiceAttributeTypes}, []ConnectedSecurityServiceModel{
+		{Name: types.StringValue("cloud-posture"), InstanceIds: types.SetNull(types.StringType)},
+		{Name: types.StringValue("extra"), InstanceIds: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("instance-1")})},
+		{Name: types.StringValue("missing"), InstanceIds: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("instance-2")})},
+	})
+	if diags.HasError() {
+		t.Fatalf("failed to build the configured services: %v", diags)
+	}
+
+	services, diags := keepConfiguredInstanceIds(ctx, configured, []common.ConnectedSecurityService{
+		{Name: tea.String("cloud-posture"), InstanceIds: []string{"posture-1"}},
+		{Name: tea.String("extra"), InstanceIds: []string{"instance-1", "instance-visionone"}},
+		{Name: tea.String("missing"), InstanceIds: []string{"instance-visionone"}},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	expected := map[string][]string{
+		"cloud-posture": {"posture-1"},
+		"extra":         {"instance-1"},
+		"missing":       {"instance-visionone"},
+	}
+	for _, service := range services {
+		if !slices.Equal(service.InstanceIds, expected[tea.StringValue(service.Name)]) {
+			t.Errorf("expected the instances %v for %s, got %v", expected[tea.StringValue(service.Name)], tea.StringValue(service.Name), service.InstanceIds)
+		}
+	}
+}
+
+func TestFlattenConnectedSecurityServicesIgnoresInstanceOrder(t *testing.T) {
+	ctx := context.Background()
+	flatten := func(instanceIds ...string) types.Set {
+		set, diags := flattenConnectedSecurityServices(ctx, []common.ConnectedSecurityService{
+			{Name: tea.String("agentless-vulnerability-threat-detection"), InstanceIds: instanceIds},
+		})
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		return set
+	}
+
+	if !flatten("instance-1", "instance-2").Equal(flatten("instance-2", "instance-1")) {
+		t.Error("expected the order of the instances to be ignored")
+	}
+}
+
+func TestConnectedAccountResourceModifyPlanUpdatedDateTime(t *testing.T) {
+	ctx := context.Background()
+	r := &connectedAccountResource{}
+	state := newTestConnectedAccountState(t, r)
+
+	for attribute, tt := range map[string]struct {
+		value   attr.Value
+		unknown bool
+	}{
+		"name":                 {value: types.StringValue("renamed"), unknown: true},
+		"description":          {value: types.StringValue("described"), unknown: true},
+		"sync_stale_threshold": {value: types.StringValue("1h"), unknown: false},
+	} {
+		t.Run(attribute, func(t *testing.T) {
+			plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+			if diags := plan.SetAttribute(ctx, path.Root(attribute), tt.value); diags.HasError() {
+				t.Fatalf("failed to set plan: %v", diags)
+			}
+
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: state}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var updatedDateTime timetypes.RFC3339
+			resp.Plan.GetAttribute(ctx, path.Root("updated_date_time"), &updatedDateTime)
+			if updatedDateTime.IsUnknown() != tt.unknown {
+				t.Errorf("expected an unknown updated_date_time: %t, got %s", tt.unknown, updatedDateTime)
+			}
+		})
+	}
+}
+
+func TestConnectedAccountResourceUpdateSkipsLocalAttributes(t *testing.T) {
+	ctx := context.Background()
+	// The server fails the test on a PATCH request
+	server, _ := newTestConnectionStateServer(t, common.ConnectionStateManaged)
+	defer server.Close()
+
+	r := newTestConnectedAccountResource(t, server)
+	state := newTestConnectedAccountState(t, r)
+	plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+	for attribute, value := range map[string]attr.Value{
+		"sync_stale_threshold":  types.StringValue("1h"),
+		"adopt_existing":        types.BoolValue(true),
+		"verify_alicloud_trust": types.BoolValue(true),
+	} {
+		if diags := plan.SetAttribute(ctx, path.Root(attribute), value); diags.HasError() {
+			t.Fatalf("failed to set plan: %v", diags)
+		}
+	}
+
+	resp := &resource.UpdateResponse{State: state}
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var updated connectedAccountResourceModel
+	resp.State.Get(ctx, &updated)
+	if updated.SyncStaleThreshold.ValueString() != "1h" {
+		t.Errorf("expected the new sync_stale_threshold in the state, got %s", updated.SyncStaleThreshold)
+	}
+}
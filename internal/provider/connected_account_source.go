@@ -4,8 +4,10 @@ import (
 	"context"
 	"terraform-provider-alicloudsecurity/internal/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -24,15 +26,20 @@ type connectedAccountSource struct {
 }
 
 type connectedAccountSourceModel struct {
-	AccountId      types.String `tfsdk:"account_id"`       // The ID of the AliCloud Account.
-	RoleArn        types.String `tfsdk:"role_arn"`         // The ARN of the role in AliCloud Account. *required*
-	OidcProviderId types.String `tfsdk:"oidc_provider_id"` // The ID of the OIDC provider in AliCloud Account. *required*
-	Name           types.String `tfsdk:"name"`             // The name of the connected account in VisionOne. *required*
-	Description    types.String `tfsdk:"description"`      // The description of the connected account in VisionOne
+	AccountId        types.String `tfsdk:"account_id"`         // The ID of the AliCloud Account.
+	StackStateRegion types.String `tfsdk:"stack_state_region"` // The region of the AliCloud Account where the terraform state is located.
+	RoleArn          types.String `tfsdk:"role_arn"`           // The ARN of the role in AliCloud Account. *required*
+	OidcProviderId   types.String `tfsdk:"oidc_provider_id"`   // The ID of the OIDC provider in AliCloud Account. *required*
+	Name             types.String `tfsdk:"name"`               // The name of the connected account in VisionOne. *required*
+	Description      types.String `tfsdk:"description"`        // The description of the connected account in VisionOne
 
-	ConnectionState types.String `tfsdk:"connection_state"`  // The state of the connected account in VisionOne
-	CreatedDateTime types.String `tfsdk:"created_date_time"` // The creation time of the connected account in VisionOne
-	UpdatedDateTime types.String `tfsdk:"updated_date_time"` // The last update time of the connected account in VisionOne
+	ConnectionState types.String      `tfsdk:"connection_state"`  // The state of the connected account in VisionOne
+	CreatedDateTime timetypes.RFC3339 `tfsdk:"created_date_time"` // The creation time of the connected account in VisionOne
+	UpdatedDateTime timetypes.RFC3339 `tfsdk:"updated_date_time"` // The last update time of the connected account in VisionOne
+
+	SyncStaleThreshold types.String      `tfsdk:"sync_stale_threshold"`  // How long after the last sync the connected account is considered stale
+	LastSyncedDateTime timetypes.RFC3339 `tfsdk:"last_synced_date_time"` // The last time VisionOne synced the connected account
+	SyncStale          types.Bool        `tfsdk:"sync_stale"`            // Whether the last sync is older than the sync_stale_threshold
 }
 
 func (c *connectedAccountSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -48,6 +55,12 @@ func (c *connectedAccountSource) Schema(_ context.Context, _ datasource.SchemaRe
 				Required:    true,
 				Computed:    false,
 			},
+			"stack_state_region": schema.StringAttribute{
+				Description: "The region of the AliCloud Account where the terraform state is located.",
+				Required:    false,
+				Optional:    false,
+				Computed:    true,
+			},
 			"role_arn": schema.StringAttribute{
 				Description: "The ARN of the role in AliCloud Account.",
 				Required:    false,
@@ -79,13 +92,35 @@ func (c *connectedAccountSource) Schema(_ context.Context, _ datasource.SchemaRe
 				Computed:    true,
 			},
 			"created_date_time": schema.StringAttribute{
-				Description: "The creation time of the connected account in VisionOne.",
+				Description: "The creation time of the connected account in VisionOne, an RFC3339 timestamp.",
+				CustomType:  timetypes.RFC3339Type{},
 				Required:    false,
 				Optional:    false,
 				Computed:    true,
 			},
 			"updated_date_time": schema.StringAttribute{
-				Description: "The last update time of the connected account in VisionOne.",
+				Description: "The last update time of the connected account in VisionOne, an RFC3339 timestamp.",
+				CustomType:  timetypes.RFC3339Type{},
+				Required:    false,
+				Optional:    false,
+				Computed:    true,
+			},
+			"sync_stale_threshold": schema.StringAttribute{
+				Description: "How long after the last sync, like 24h or 90m, the connected account is considered stale. Defaults to 24h.",
+				Optional:    true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"last_synced_date_time": schema.StringAttribute{
+				Description: "The last time VisionOne synced the resources of the connected account, an RFC3339 timestamp. Null until the first sync.",
+				CustomType:  timetypes.RFC3339Type{},
+				Required:    false,
+				Optional:    false,
+				Computed:    true,
+			},
+			"sync_stale": schema.BoolAttribute{
+				Description: "Whether the last sync of the connected account, or its creation if it was never synced, is older than sync_stale_threshold.",
 				Required:    false,
 				Optional:    false,
 				Computed:    true,
@@ -131,23 +166,23 @@ func (c *connectedAccountSource) Read(ctx context.Context, req datasource.ReadRe
 	// Read the connected account from the API
 	readConnectionResp, err := c.cam.ReadConnection(ctx, data.AccountId.ValueStringPointer())
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"API Error",
-			"Unable to read connected account: "+err.Error(),
-		)
+		addCamErrorDiagnostic(&resp.Diagnostics, "API Error", "Unable to read connected account", err)
 		return
 	}
 	// Check if the response is empty
 	if readConnectionResp != nil {
 		// Map the response to the model
 		data.AccountId = types.StringValue(*readConnectionResp.Id)
+		data.StackStateRegion = types.StringValue(*readConnectionResp.ParentStackRegion)
 		data.RoleArn = types.StringValue(*readConnectionResp.RoleArn)
 		data.OidcProviderId = types.StringValue(*readConnectionResp.OidcProviderId)
 		data.Name = types.StringValue(*readConnectionResp.Name)
 		data.Description = types.StringValue(*readConnectionResp.Description)
 		data.ConnectionState = types.StringValue(*readConnectionResp.State)
-		data.CreatedDateTime = types.StringValue(*readConnectionResp.CreatedDateTime)
-		data.UpdatedDateTime = types.StringValue(*readConnectionResp.UpdatedDateTime)
+		data.CreatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.CreatedDateTime)
+		data.UpdatedDateTime = timetypes.NewRFC3339TimePointerValue(readConnectionResp.UpdatedDateTime)
+		data.LastSyncedDateTime, data.SyncStale, diags = connectionSyncHealth(readConnectionResp, data.SyncStaleThreshold)
+		resp.Diagnostics.Append(diags...)
 	}
 
 	// set the state
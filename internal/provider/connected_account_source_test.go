@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccConnectedAccountSource(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config: cam.ProviderConfig("express") + testAccConnectedAccountConfig("test", "source") + `
+data "alicloudsecurity_connected_account" "test" {
+  account_id = alicloudsecurity_connected_account.test.account_id
+}
+
+data "alicloudsecurity_connected_accounts" "test" {
+  name       = alicloudsecurity_connected_account.test.name
+  depends_on = [alicloudsecurity_connected_account.test]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.alicloudsecurity_connected_account.test", "role_arn", "alicloudsecurity_connected_account.test", "role_arn"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.test", "name", "test"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.test", "description", "source"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.test", "connection_state", "managed"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_accounts.test", "accounts.#", "1"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_accounts.test", "accounts.0.account_id", fakeStsAccountId),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.test", "stack_state_region", "us-east-1"),
+					resource.TestCheckResourceAttrSet("data.alicloudsecurity_connected_account.test", "last_synced_date_time"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.test", "sync_stale", "false"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_accounts.test", "accounts.0.sync_stale", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountSourceSyncStale(t *testing.T) {
+	setTestConnectionStatePollInterval(t)
+	cam := newFakeCamServer(t)
+
+	config := cam.ProviderConfig("express") + testAccConnectedAccountConfig("test", "source") + `
+data "alicloudsecurity_connected_account" "default" {
+  account_id = alicloudsecurity_connected_account.test.account_id
+}
+
+data "alicloudsecurity_connected_account" "lenient" {
+  account_id           = alicloudsecurity_connected_account.test.account_id
+  sync_stale_threshold = "72h"
+}
+
+data "alicloudsecurity_connected_accounts" "test" {
+  sync_stale_threshold = "36h"
+  depends_on           = [alicloudsecurity_connected_account.test]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckConnectedAccountDestroyed(cam),
+		Steps: []resource.TestStep{
+			{
+				Config: cam.ProviderConfig("express") + testAccConnectedAccountConfig("test", "source"),
+			},
+			{
+				PreConfig: func() {
+					cam.SetLastSynced(fakeStsAccountId, time.Now().Add(-48*time.Hour))
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.default", "sync_stale", "true"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_account.lenient", "sync_stale", "false"),
+					resource.TestCheckResourceAttr("data.alicloudsecurity_connected_accounts.test", "accounts.0.sync_stale", "true"),
+					resource.TestCheckResourceAttrPair("data.alicloudsecurity_connected_account.default", "last_synced_date_time",
+						"data.alicloudsecurity_connected_accounts.test", "accounts.0.last_synced_date_time"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConnectedAccountsSourceEmpty(t *testing.T) {
+	cam := newFakeCamServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: cam.ProviderConfig("automation") + fmt.Sprintf(`
+data "alicloudsecurity_connected_accounts" "test" {
+  connection_state = %q
+}
+`, "managed"),
+				Check: resource.TestCheckResourceAttr("data.alicloudsecurity_connected_accounts.test", "accounts.#", "0"),
+			},
+		},
+	})
+}
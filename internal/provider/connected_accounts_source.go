@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &connectedAccountsSource{}
+	_ datasource.DataSourceWithConfigure = &connectedAccountsSource{}
+)
+
+func NewConnectedAccountsSource() datasource.DataSource {
+	return &connectedAccountsSource{}
+}
+
+type connectedAccountsSource struct {
+	cam *common.CamClient
+}
+
+type connectedAccountsSourceModel struct {
+	ConnectionState    types.String                 `tfsdk:"connection_state"`     // Only list the connected accounts in this state
+	Name               types.String                 `tfsdk:"name"`                 // Only list the connected accounts with this name
+	SyncStaleThreshold types.String                 `tfsdk:"sync_stale_threshold"` // How long after the last sync the connected accounts are considered stale
+	Accounts           []connectedAccountsItemModel `tfsdk:"accounts"`             // The connected accounts matching the filters
+}
+
+type connectedAccountsItemModel struct {
+	AccountId        types.String `tfsdk:"account_id"`         // The ID of the AliCloud Account.
+	StackStateRegion types.String `tfsdk:"stack_state_region"` // The region of the AliCloud Account where the terraform state is located.
+	RoleArn          types.String `tfsdk:"role_arn"`           // The ARN of the role in AliCloud Account.
+	OidcProviderId   types.String `tfsdk:"oidc_provider_id"`   // The ID of the OIDC provider in AliCloud Account.
+	Name             types.String `tfsdk:"name"`               // The name of the connected account in VisionOne.
+	Description      types.String `tfsdk:"description"`        // The description of the connected account in VisionOne
+
+	ConnectionState types.String      `tfsdk:"connection_state"`  // The state of the connected account in VisionOne
+	CreatedDateTime timetypes.RFC3339 `tfsdk:"created_date_time"` // The creation time of the connected account in VisionOne
+	UpdatedDateTime timetypes.RFC3339 `tfsdk:"updated_date_time"` // The last update time of the connected account in VisionOne
+
+	LastSyncedDateTime timetypes.RFC3339 `tfsdk:"last_synced_date_time"` // The last time VisionOne synced the connected account
+	SyncStale          types.Bool        `tfsdk:"sync_stale"`            // Whether the last sync is older than the sync_stale_threshold
+}
+
+func (c *connectedAccountsSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_connected_accounts"
+}
+
+func (c *connectedAccountsSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source listing the AliCloud Accounts connected to VisionOne.",
+		Attributes: map[string]schema.Attribute{
+			"connection_state": schema.StringAttribute{
+				Description: "Only list the connected accounts in this state, such as managed or failed.",
+				Optional:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "Only list the connected accounts with this name in VisionOne.",
+				Optional:    true,
+			},
+			"sync_stale_threshold": schema.StringAttribute{
+				Description: "How long after the last sync, like 24h or 90m, the connected accounts are considered stale. Defaults to 24h.",
+				Optional:    true,
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"accounts": schema.ListNestedAttribute{
+				Description: "The connected accounts matching the filters.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"account_id": schema.StringAttribute{
+							Description: "The ID of the connected AliCloud Account.",
+							Computed:    true,
+						},
+						"stack_state_region": schema.StringAttribute{
+							Description: "The region of the AliCloud Account where the terraform state is located.",
+							Computed:    true,
+						},
+						"role_arn": schema.StringAttribute{
+							Description: "The ARN of the role in AliCloud Account.",
+							Computed:    true,
+						},
+						"oidc_provider_id": schema.StringAttribute{
+							Description: "The ID of the OIDC provider in AliCloud Account.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the connected account in VisionOne.",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "The description of the connected account in VisionOne.",
+							Computed:    true,
+						},
+						"connection_state": schema.StringAttribute{
+							Description: "The state of the connected account in VisionOne.",
+							Computed:    true,
+						},
+						"created_date_time": schema.StringAttribute{
+							Description: "The creation time of the connected account in VisionOne, an RFC3339 timestamp.",
+							CustomType:  timetypes.RFC3339Type{},
+							Computed:    true,
+						},
+						"updated_date_time": schema.StringAttribute{
+							Description: "The last update time of the connected account in VisionOne, an RFC3339 timestamp.",
+							CustomType:  timetypes.RFC3339Type{},
+							Computed:    true,
+						},
+						"last_synced_date_time": schema.StringAttribute{
+							Description: "The last time VisionOne synced the resources of the connected account, an RFC3339 timestamp. Null until the first sync.",
+							CustomType:  timetypes.RFC3339Type{},
+							Computed:    true,
+						},
+						"sync_stale": schema.BoolAttribute{
+							Description: "Whether the last sync of the connected account, or its creation if it was never synced, is older than sync_stale_threshold.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure prepares the provider for data source operations.
+func (c *connectedAccountsSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	clients := req.ProviderData.(*aliCloudSecurityProviderClients)
+	if clients == nil {
+		resp.Diagnostics.AddError(
+			"Client Error",
+			"Client configuration is not set up properly. Please configure the provider.",
+		)
+		return
+	}
+	c.cam = clients.visiononeClients.Cam
+}
+
+func (c *connectedAccountsSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data connectedAccountsSourceModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// List the connected accounts from the API, filtered by the server
+	connections, err := c.cam.ListConnections(ctx, &common.ListConnectionsRequest{
+		State: data.ConnectionState.ValueStringPointer(),
+		Name:  data.Name.ValueStringPointer(),
+	})
+	if err != nil {
+		addCamErrorDiagnostic(&resp.Diagnostics, "API Error", "Unable to list connected accounts", err)
+		return
+	}
+
+	// Map the response to the model
+	data.Accounts = make([]connectedAccountsItemModel, 0, len(connections))
+	for _, connection := range connections {
+		lastSyncedDateTime, syncStale, diags := connectionSyncHealth(connection, data.SyncStaleThreshold)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.Accounts = append(data.Accounts, connectedAccountsItemModel{
+			AccountId:        types.StringValue(*connection.Id),
+			StackStateRegion: types.StringValue(*connection.ParentStackRegion),
+			RoleArn:          types.StringValue(*connection.RoleArn),
+			OidcProviderId:   types.StringValue(*connection.OidcProviderId),
+			Name:             types.StringValue(*connection.Name),
+			Description:      types.StringValue(*connection.Description),
+			ConnectionState:  types.StringValue(*connection.State),
+			CreatedDateTime:  timetypes.NewRFC3339TimePointerValue(connection.CreatedDateTime),
+			UpdatedDateTime:  timetypes.NewRFC3339TimePointerValue(connection.UpdatedDateTime),
+
+			LastSyncedDateTime: lastSyncedDateTime,
+			SyncStale:          syncStale,
+		})
+	}
+
+	// set the state
+	diags = resp.State.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// camErrorHints are the hints added to the diagnostics of well-known VisionOne API errors.
+var camErrorHints = map[int]string{
+	http.StatusUnauthorized: "VisionOne rejected the API key. Check that visionone_api_key is valid, not expired, " +
+		"and issued for the region of visionone_endpoint.",
+	http.StatusForbidden: "The VisionOne API key is missing a permission. Check that the role of the API key " +
+		"is allowed to manage Cloud Account Management accounts.",
+}
+
+// camAlreadyExistsHint is added to the diagnostics of the errors reporting that the AliCloud
+// Account is already connected, whatever their status code.
+const camAlreadyExistsHint = "The AliCloud Account is already connected to VisionOne. Import it with terraform import, " +
+	"set adopt_existing = true to adopt the connection if it is not managed by another workspace, " +
+	"or disconnect it in the VisionOne console first."
+
+// addCamErrorDiagnostic adds an error diagnostic for a failed CAM API call. The detail is
+// followed by the error and, for well-known VisionOne errors, a hint on how to resolve it.
+func addCamErrorDiagnostic(diags *diag.Diagnostics, summary, detail string, err error) {
+	message := detail + ": " + err.Error()
+
+	var apiErr *common.CamAPIError
+	if common.IsCamAPIErrorAlreadyExists(err) {
+		message += "\n\n" + camAlreadyExistsHint
+	} else if errors.As(err, &apiErr) {
+		if hint, ok := camErrorHints[apiErr.StatusCode]; ok {
+			message += "\n\n" + hint
+		}
+	}
+
+	diags.AddError(summary, message)
+}
@@ -5,23 +5,29 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"os"
 	"terraform-provider-alicloudsecurity/internal/common"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider              = &aliCloudSecurityProvider{}
-	_ provider.ProviderWithFunctions = &aliCloudSecurityProvider{}
+	_ provider.Provider                     = &aliCloudSecurityProvider{}
+	_ provider.ProviderWithFunctions        = &aliCloudSecurityProvider{}
+	_ provider.ProviderWithConfigValidators = &aliCloudSecurityProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -48,11 +54,25 @@ type aliCloudSecurityProviderClients struct {
 
 // aliCloudSecurityProviderModel maps provider schema data to a Go type.
 type aliCloudSecurityProviderModel struct {
-	VisiononeEndpoint     types.String `tfsdk:"visionone_endpoint"`
-	VisiononeEndpointType types.String `tfsdk:"visionone_endpoint_type"`
-	VisiononeBusinessId   types.String `tfsdk:"visionone_business_id"`
-	VisiononeAPIKey       types.String `tfsdk:"visionone_api_key"`
-	VisiononeRegion       types.String `tfsdk:"visionone_region"`
+	VisiononeEndpoint     types.String  `tfsdk:"visionone_endpoint"`
+	VisiononeEndpointType types.String  `tfsdk:"visionone_endpoint_type"`
+	VisiononeBusinessId   types.String  `tfsdk:"visionone_business_id"`
+	VisiononeAPIKey       types.String  `tfsdk:"visionone_api_key"`
+	VisiononeRegion       types.String  `tfsdk:"visionone_region"`
+	MaxRetries            types.Int64   `tfsdk:"max_retries"`
+	RetryMinBackoff       types.Int64   `tfsdk:"retry_min_backoff"`
+	RetryMaxBackoff       types.Int64   `tfsdk:"retry_max_backoff"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	MaxInFlightRequests   types.Int64   `tfsdk:"max_in_flight_requests"`
+	RequestTimeout        types.Int64   `tfsdk:"request_timeout"`
+	HttpProxy             types.String  `tfsdk:"http_proxy"`
+	CaBundleFile          types.String  `tfsdk:"ca_bundle_file"`
+	CaBundle              types.String  `tfsdk:"ca_bundle"`
+	ClientCertificateFile types.String  `tfsdk:"client_certificate_file"`
+	ClientKeyFile         types.String  `tfsdk:"client_key_file"`
+	InsecureSkipVerify    types.Bool    `tfsdk:"insecure_skip_verify"`
+
+	AliCloud *aliCloudProviderModel `tfsdk:"alicloud"`
 }
 
 // Metadata returns the provider type name.
@@ -67,12 +87,15 @@ func (p *aliCloudSecurityProvider) Schema(_ context.Context, _ provider.SchemaRe
 		Description: "Interact with VisionOne AliCloud Security.",
 		Attributes: map[string]schema.Attribute{
 			"visionone_endpoint": schema.StringAttribute{
-				Description: "Endpoint for VisionOne AliCloud Security. May also be provided via VISIONONE_ENDPOINT environment variable.",
+				Description: "Endpoint for VisionOne AliCloud Security. Defaults to the automation API of visionone_region for the automation endpoint type, set it for the express endpoint type or a private stack. May also be provided via VISIONONE_ENDPOINT environment variable.",
 				Optional:    true,
 			},
 			"visionone_endpoint_type": schema.StringAttribute{
-				Description: "Endpoint type for VisionOne AliCloud Security. May also be provided via VISIONONE_ENDPOINT_TYPE environment variable.",
+				Description: "Endpoint type for VisionOne AliCloud Security, either automation or express. Defaults to automation. May also be provided via VISIONONE_ENDPOINT_TYPE environment variable.",
 				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(common.EndpointTypes()...),
+				},
 			},
 			"visionone_business_id": schema.StringAttribute{
 				Description: "Bussiness Id for VisionOne AliCloud Security. May also be provided via VISIONONE_BUSINESS_ID environment variable.",
@@ -81,18 +104,67 @@ func (p *aliCloudSecurityProvider) Schema(_ context.Context, _ provider.SchemaRe
 			"visionone_api_key": schema.StringAttribute{
 				Description: "API key for VisionOne AliCloud Security. May also be provided via VISIONONE_API_KEY environment variable.",
 				Optional:    true,
+				Sensitive:   true,
 			},
 			"visionone_region": schema.StringAttribute{
-				Description: "Region for VisionOne AliCloud Security. May also be provided via VISIONONE_REGION environment variable.",
+				Description: "Region for VisionOne AliCloud Security, one of us, eu, jp, sg, au, in or mea. May also be provided via VISIONONE_REGION environment variable.",
 				Optional:    true,
 			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Maximum number of retries of a VisionOne API request that failed with a transient error, such as a 429, 502, 503, 504 or a connection reset. Set to 0 to disable retries. Defaults to 3.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_min_backoff": schema.Int64Attribute{
+				Description: "Backoff in seconds before the first retry of a VisionOne API request. The backoff doubles on every following retry. Defaults to 1.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_max_backoff": schema.Int64Attribute{
+				Description: "Maximum backoff in seconds between two retries of a VisionOne API request, unless the API asks for a longer wait with a Retry-After header. Defaults to 30.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "Average number of VisionOne API requests sent per second by the provider, shared by all the resources. Set to 0 to disable the limit. Defaults to 10.",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0),
+				},
+			},
+			"max_in_flight_requests": schema.Int64Attribute{
+				Description: "Maximum number of VisionOne API requests waiting for their response, shared by all the resources. Set to 0 to disable the limit. Defaults to 10.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
 		},
+		Blocks: map[string]schema.Block{
+			"alicloud": aliCloudProviderBlock(),
+		},
+	}
+	for name, attribute := range transportProviderAttributes() {
+		resp.Schema.Attributes[name] = attribute
+	}
+}
+
+// ConfigValidators returns the validations of the provider configuration across attributes.
+func (p *aliCloudSecurityProvider) ConfigValidators(_ context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		retryBackoffValidator{},
 	}
 }
 
 // Configure prepares a VisionOne AliCloud Security API client for data sources and resources.
 func (p *aliCloudSecurityProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	tflog.Info(ctx, "Configuring VisionOne AliCloud Security client")
+	common.LogInfo(ctx, "Configuring VisionOne AliCloud Security client")
 
 	// Retrieve provider data from configuration
 	var config aliCloudSecurityProviderModel
@@ -183,23 +255,38 @@ func (p *aliCloudSecurityProvider) Configure(ctx context.Context, req provider.C
 
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
-	if visionone_endpoint == "" {
+	// The endpoint type defaults to automation, whose endpoint is derived from the region
+	if visionone_endpoint_type == "" {
+		visionone_endpoint_type = common.EndpointTypeAutomation
+	}
+
+	profile, err := common.GetEndpointProfile(visionone_endpoint_type)
+	if err != nil {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("visionone_endpoint"),
-			"Missing VisionOne Endpoint",
-			"The provider cannot create the VisionOne API client as there is a missing or empty value for the VisionOne endpoint. "+
-				"Set the visionone_endpoint value in the configuration or use the VISIONONE_ENDPOINT environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			path.Root("visionone_endpoint_type"),
+			"Invalid VisionOne Endpoint Type",
+			"The provider cannot create the VisionOne API client as the VisionOne endpoint type is not supported: "+err.Error()+". "+
+				"Fix the visionone_endpoint_type value in the configuration or the VISIONONE_ENDPOINT_TYPE environment variable.",
 		)
+	} else if visionone_endpoint == "" && visionone_region != "" {
+		visionone_endpoint, err = profile.DefaultEndpoint(visionone_region)
+		if err != nil && !errors.Is(err, common.ErrNoDefaultEndpoint) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("visionone_region"),
+				"Invalid VisionOne Region",
+				"The provider cannot derive the VisionOne endpoint from the VisionOne region: "+err.Error()+". "+
+					"Fix the visionone_region value, or set the visionone_endpoint value for a private stack.",
+			)
+		}
 	}
 
-	if visionone_endpoint_type == "" {
+	if visionone_endpoint == "" && !resp.Diagnostics.HasError() {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("visionone_endpoint_type"),
-			"Missing VisionOne Endpoint Type",
-			"The provider cannot create the VisionOne API client as there is a missing or empty value for the VisionOne endpoint type. "+
-				"Set the visionone_endpoint_type value in the configuration or use the VISIONONE_ENDPOINT_TYPE environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			path.Root("visionone_endpoint"),
+			"Missing VisionOne Endpoint",
+			"The provider cannot create the VisionOne API client as there is a missing or empty value for the VisionOne endpoint. "+
+				"Set the visionone_endpoint value in the configuration or use the VISIONONE_ENDPOINT environment variable. "+
+				"The endpoint is only derived from the visionone_region value for the automation endpoint type.",
 		)
 	}
 
@@ -233,27 +320,45 @@ func (p *aliCloudSecurityProvider) Configure(ctx context.Context, req provider.C
 		)
 	}
 
+	retryPolicy := common.DefaultRetryPolicy()
+	if !config.MaxRetries.IsNull() {
+		retryPolicy.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.RetryMinBackoff.IsNull() {
+		retryPolicy.MinBackoff = time.Duration(config.RetryMinBackoff.ValueInt64()) * time.Second
+	}
+	if !config.RetryMaxBackoff.IsNull() {
+		retryPolicy.MaxBackoff = time.Duration(config.RetryMaxBackoff.ValueInt64()) * time.Second
+	}
+
+	rateLimit := common.DefaultRateLimit()
+	if !config.RequestsPerSecond.IsNull() {
+		rateLimit.RequestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+	}
+	if !config.MaxInFlightRequests.IsNull() {
+		rateLimit.MaxInFlightRequests = int(config.MaxInFlightRequests.ValueInt64())
+	}
+
+	transportConfig, diags := buildTransportConfig(config)
+	resp.Diagnostics.Append(diags...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ctx = tflog.SetField(ctx, "visionone_endpoint", visionone_endpoint_type)
-	ctx = tflog.SetField(ctx, "visionone_endpoint_type", visionone_endpoint_type)
-	ctx = tflog.SetField(ctx, "visionone_business_id", visionone_business_id)
-	ctx = tflog.SetField(ctx, "visionone_api_key", visionone_api_key)
-	ctx = tflog.SetField(ctx, "visionone_region", visionone_region)
-	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "visionone_api_key")
+	ctx = common.SetLogField(ctx, "visionone_endpoint", visionone_endpoint)
+	ctx = common.SetLogField(ctx, "visionone_endpoint_type", visionone_endpoint_type)
+	ctx = common.SetLogField(ctx, "visionone_business_id", visionone_business_id)
+	ctx = common.SetLogField(ctx, "visionone_region", visionone_region)
 
-	tflog.Debug(ctx, "Creating VisionOne API client", map[string]any{
-		"visionone_endpoint":      visionone_endpoint,
-		"visionone_endpoint_type": visionone_endpoint_type,
-		"visionone_business_id":   visionone_business_id,
-		"visionone_api_key":       visionone_api_key,
-		"visionone_region":        visionone_region,
-	})
+	common.LogDebug(ctx, "Creating VisionOne API client")
 
-	visiononeClients := &common.VisionOneClients{}
-	_, err := visiononeClients.Build(visionone_endpoint, visionone_endpoint_type, visionone_business_id, visionone_api_key, visionone_region)
+	visiononeClients := &common.VisionOneClients{
+		RetryPolicy: retryPolicy,
+		Transport:   transportConfig,
+		RateLimit:   rateLimit,
+	}
+	_, err = visiononeClients.Build(ctx, visionone_endpoint, visionone_endpoint_type, visionone_business_id, visionone_api_key, visionone_region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create VisionOne API client",
@@ -262,16 +367,22 @@ func (p *aliCloudSecurityProvider) Configure(ctx context.Context, req provider.C
 		return
 	}
 
-	alicloudClients := &common.AliCloudClients{}
-	_, err = alicloudClients.Build()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to create AliCloud API client",
-			"Unable to create AliCloud API client: "+err.Error(),
-		)
+	alicloudConfig, diags := buildAliCloudClientConfig(config.AliCloud)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	// The AliCloud clients are built on first use by the resources that need them, so that
+	// managing the VisionOne side only does not require AliCloud credentials.
+	alicloudClients := &common.AliCloudClients{
+		Config:    alicloudConfig,
+		Transport: transportConfig,
+	}
+	if config.AliCloud != nil {
+		alicloudClients.SkipCredentialsValidation = config.AliCloud.SkipCredentialsValidation.ValueBool()
+	}
+
 	clients := &aliCloudSecurityProviderClients{
 		visiononeClients: visiononeClients,
 		alicloudClients:  alicloudClients,
@@ -282,13 +393,14 @@ func (p *aliCloudSecurityProvider) Configure(ctx context.Context, req provider.C
 	resp.DataSourceData = clients
 	resp.ResourceData = clients
 
-	tflog.Info(ctx, "Configured VisionOne API client", map[string]any{"success": true})
+	common.LogInfo(ctx, "Configured VisionOne API client", map[string]any{"success": true})
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *aliCloudSecurityProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewConnectedAccountSource, // temporary data source for test
+		NewConnectedAccountsSource,
 	}
 }
 
@@ -296,9 +408,15 @@ func (p *aliCloudSecurityProvider) DataSources(_ context.Context) []func() datas
 func (p *aliCloudSecurityProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewConnectedAccountResource,
+		NewVisionOneTrustResource,
 	}
 }
 
 func (p *aliCloudSecurityProvider) Functions(_ context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewParseArnFunction,
+		NewBuildRoleArnFunction,
+		NewBuildOidcProviderArnFunction,
+		NewVisionOneTrustPolicyFunction,
+	}
 }
@@ -0,0 +1,212 @@
+package provider
+
+import (
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// aliCloudProviderModel maps the alicloud block of the provider schema.
+type aliCloudProviderModel struct {
+	AccessKey                 types.String                     `tfsdk:"access_key"`
+	SecretKey                 types.String                     `tfsdk:"secret_key"`
+	SecurityToken             types.String                     `tfsdk:"security_token"`
+	Region                    types.String                     `tfsdk:"region"`
+	Profile                   types.String                     `tfsdk:"profile"`
+	SharedCredentialsFile     types.String                     `tfsdk:"shared_credentials_file"`
+	EcsRoleName               types.String                     `tfsdk:"ecs_role_name"`
+	StsEndpoint               types.String                     `tfsdk:"sts_endpoint"`
+	RamEndpoint               types.String                     `tfsdk:"ram_endpoint"`
+	ImsEndpoint               types.String                     `tfsdk:"ims_endpoint"`
+	SkipCredentialsValidation types.Bool                       `tfsdk:"skip_credentials_validation"`
+	AssumeRole                *aliCloudAssumeRoleModel         `tfsdk:"assume_role"`
+	AssumeRoleWithOidc        *aliCloudAssumeRoleWithOidcModel `tfsdk:"assume_role_with_oidc"`
+}
+
+// aliCloudAssumeRoleModel maps the assume_role block of the alicloud block.
+type aliCloudAssumeRoleModel struct {
+	RoleArn           types.String `tfsdk:"role_arn"`
+	SessionName       types.String `tfsdk:"session_name"`
+	ExternalId        types.String `tfsdk:"external_id"`
+	SessionExpiration types.Int64  `tfsdk:"session_expiration"`
+}
+
+// aliCloudAssumeRoleWithOidcModel maps the assume_role_with_oidc block of the alicloud block.
+type aliCloudAssumeRoleWithOidcModel struct {
+	RoleArn         types.String `tfsdk:"role_arn"`
+	OidcProviderArn types.String `tfsdk:"oidc_provider_arn"`
+	OidcTokenFile   types.String `tfsdk:"oidc_token_file"`
+	SessionName     types.String `tfsdk:"session_name"`
+}
+
+// aliCloudProviderBlock returns the schema of the alicloud block of the provider.
+func aliCloudProviderBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		Description: "Credentials for the AliCloud APIs. When no credential is set, the default credential chain is used: " +
+			"the ALIBABA_CLOUD_* environment variables, the RRSA OIDC token, the ~/.aliyun/config.json profile and the ECS instance RAM role.",
+		Attributes: map[string]schema.Attribute{
+			"access_key": schema.StringAttribute{
+				Description: "Access key ID of AliCloud. May also be provided via ALICLOUD_ACCESS_KEY environment variable.",
+				Optional:    true,
+			},
+			"secret_key": schema.StringAttribute{
+				Description: "Access key secret of AliCloud. May also be provided via ALICLOUD_ACCESS_SECRET environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"security_token": schema.StringAttribute{
+				Description: "STS security token of a temporary access key. May also be provided via ALICLOUD_SECURITY_TOKEN environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"region": schema.StringAttribute{
+				Description: "Region of AliCloud. May also be provided via ALICLOUD_REGION environment variable.",
+				Optional:    true,
+			},
+			"profile": schema.StringAttribute{
+				Description: "Name of the profile in the Alibaba Cloud CLI configuration file. May also be provided via ALICLOUD_PROFILE environment variable.",
+				Optional:    true,
+			},
+			"shared_credentials_file": schema.StringAttribute{
+				Description: "Path of the Alibaba Cloud CLI configuration file, ~/.aliyun/config.json by default. May also be provided via ALICLOUD_SHARED_CREDENTIALS_FILE environment variable.",
+				Optional:    true,
+			},
+			"ecs_role_name": schema.StringAttribute{
+				Description: "Name of the RAM role attached to the ECS instance running Terraform. May also be provided via ALICLOUD_ECS_ROLE_NAME environment variable.",
+				Optional:    true,
+			},
+			"sts_endpoint": schema.StringAttribute{
+				Description: "Endpoint of the AliCloud STS API, such as a VPC endpoint. Defaults to sts.<region>.aliyuncs.com. May also be provided via ALICLOUD_STS_ENDPOINT environment variable.",
+				Optional:    true,
+			},
+			"ram_endpoint": schema.StringAttribute{
+				Description: "Endpoint of the AliCloud RAM API. Defaults to ram.aliyuncs.com. May also be provided via ALICLOUD_RAM_ENDPOINT environment variable.",
+				Optional:    true,
+			},
+			"ims_endpoint": schema.StringAttribute{
+				Description: "Endpoint of the AliCloud IMS API, which manages the OIDC providers. Defaults to ims.aliyuncs.com. May also be provided via ALICLOUD_IMS_ENDPOINT environment variable.",
+				Optional:    true,
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				Description: "Skip the STS GetCallerIdentity check of the AliCloud credentials on first use. Defaults to false.",
+				Optional:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"assume_role": schema.SingleNestedBlock{
+				Description: "RAM role to assume with the resolved credential.",
+				Attributes: map[string]schema.Attribute{
+					"role_arn": schema.StringAttribute{
+						Description: "ARN of the RAM role to assume.",
+						Optional:    true,
+					},
+					"session_name": schema.StringAttribute{
+						Description: "Name of the role session.",
+						Optional:    true,
+					},
+					"external_id": schema.StringAttribute{
+						Description: "External ID required by the trust policy of the role.",
+						Optional:    true,
+					},
+					"session_expiration": schema.Int64Attribute{
+						Description: "Lifetime of the role session in seconds.",
+						Optional:    true,
+					},
+				},
+			},
+			"assume_role_with_oidc": schema.SingleNestedBlock{
+				Description: "RAM role to assume with an OIDC token, such as the RRSA token of an ACK pod or a GitHub Actions token.",
+				Attributes: map[string]schema.Attribute{
+					"role_arn": schema.StringAttribute{
+						Description: "ARN of the RAM role to assume. May also be provided via ALIBABA_CLOUD_ROLE_ARN environment variable.",
+						Optional:    true,
+					},
+					"oidc_provider_arn": schema.StringAttribute{
+						Description: "ARN of the OIDC identity provider trusted by the role. May also be provided via ALIBABA_CLOUD_OIDC_PROVIDER_ARN environment variable.",
+						Optional:    true,
+					},
+					"oidc_token_file": schema.StringAttribute{
+						Description: "Path of the file holding the OIDC token. May also be provided via ALIBABA_CLOUD_OIDC_TOKEN_FILE environment variable.",
+						Optional:    true,
+					},
+					"session_name": schema.StringAttribute{
+						Description: "Name of the role session.",
+						Optional:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildAliCloudClientConfig returns the configuration of the AliCloud clients, read from the
+// alicloud block of the provider. The values left unset are read from the environment
+// variables, the credentials only if the block sets none.
+func buildAliCloudClientConfig(model *aliCloudProviderModel) (*common.AliCloudClientConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	config := &common.AliCloudClientConfig{}
+	if model == nil {
+		config.SetDefaultsFromEnv()
+		return config, diags
+	}
+
+	overrideString(&config.AccessKey, model.AccessKey, path.Root("alicloud").AtName("access_key"), &diags)
+	overrideString(&config.AccessKeySecret, model.SecretKey, path.Root("alicloud").AtName("secret_key"), &diags)
+	overrideString(&config.SecurityToken, model.SecurityToken, path.Root("alicloud").AtName("security_token"), &diags)
+	overrideString(&config.Region, model.Region, path.Root("alicloud").AtName("region"), &diags)
+	overrideString(&config.Profile, model.Profile, path.Root("alicloud").AtName("profile"), &diags)
+	overrideString(&config.ProfileFile, model.SharedCredentialsFile, path.Root("alicloud").AtName("shared_credentials_file"), &diags)
+	overrideString(&config.EcsRoleName, model.EcsRoleName, path.Root("alicloud").AtName("ecs_role_name"), &diags)
+	overrideString(&config.StsEndpoint, model.StsEndpoint, path.Root("alicloud").AtName("sts_endpoint"), &diags)
+	overrideString(&config.RamEndpoint, model.RamEndpoint, path.Root("alicloud").AtName("ram_endpoint"), &diags)
+	overrideString(&config.ImsEndpoint, model.ImsEndpoint, path.Root("alicloud").AtName("ims_endpoint"), &diags)
+
+	if model.AssumeRole != nil {
+		config.AssumeRole = &common.AliCloudAssumeRoleConfig{}
+		overrideString(&config.AssumeRole.RoleArn, model.AssumeRole.RoleArn, path.Root("alicloud").AtName("assume_role").AtName("role_arn"), &diags)
+		overrideString(&config.AssumeRole.SessionName, model.AssumeRole.SessionName, path.Root("alicloud").AtName("assume_role").AtName("session_name"), &diags)
+		overrideString(&config.AssumeRole.ExternalId, model.AssumeRole.ExternalId, path.Root("alicloud").AtName("assume_role").AtName("external_id"), &diags)
+		if !model.AssumeRole.SessionExpiration.IsNull() {
+			config.AssumeRole.SessionExpiration = int(model.AssumeRole.SessionExpiration.ValueInt64())
+		}
+		if config.AssumeRole.RoleArn == "" {
+			diags.AddAttributeError(
+				path.Root("alicloud").AtName("assume_role").AtName("role_arn"),
+				"Missing AliCloud Assume Role ARN",
+				"The role_arn value must be set in the assume_role block.",
+			)
+		}
+	}
+
+	if model.AssumeRoleWithOidc != nil {
+		config.Oidc = &common.AliCloudOidcConfig{}
+		overrideString(&config.Oidc.RoleArn, model.AssumeRoleWithOidc.RoleArn, path.Root("alicloud").AtName("assume_role_with_oidc").AtName("role_arn"), &diags)
+		overrideString(&config.Oidc.OidcProviderArn, model.AssumeRoleWithOidc.OidcProviderArn, path.Root("alicloud").AtName("assume_role_with_oidc").AtName("oidc_provider_arn"), &diags)
+		overrideString(&config.Oidc.OidcTokenFile, model.AssumeRoleWithOidc.OidcTokenFile, path.Root("alicloud").AtName("assume_role_with_oidc").AtName("oidc_token_file"), &diags)
+		overrideString(&config.Oidc.SessionName, model.AssumeRoleWithOidc.SessionName, path.Root("alicloud").AtName("assume_role_with_oidc").AtName("session_name"), &diags)
+	}
+
+	config.SetDefaultsFromEnv()
+	return config, diags
+}
+
+// overrideString sets target to the configured value, if any. Unknown values are reported
+// as errors since the AliCloud clients cannot be configured with them.
+func overrideString(target *string, value types.String, attributePath path.Path, diags *diag.Diagnostics) {
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			attributePath,
+			"Unknown AliCloud Configuration",
+			"The provider cannot create the AliCloud API client as there is an unknown configuration value. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+		return
+	}
+	if !value.IsNull() {
+		*target = value.ValueString()
+	}
+}
@@ -1,8 +1,20 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 const (
@@ -27,3 +39,294 @@ var (
 		"alicloudsecurity": providerserver.NewProtocol6WithError(New("test")()),
 	}
 )
+
+func TestAccProviderInvalidEndpointType(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "alicloudsecurity" {
+  visionone_endpoint      = "https://api.xdr.trendmicro.com"
+  visionone_endpoint_type = "manual"
+  visionone_business_id   = "test-business-id"
+  visionone_api_key       = "test-api-key"
+  visionone_region        = "us"
+}
+
+data "alicloudsecurity_connected_accounts" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`value must be one of`),
+			},
+		},
+	})
+}
+
+// configureTestProvider configures the provider with the given attributes, the others are null.
+func configureTestProvider(ctx context.Context, t *testing.T, attributes map[string]string) *provider.ConfigureResponse {
+	t.Helper()
+
+	p := New("test")()
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := attributes[name]; ok {
+			values[name] = tftypes.NewValue(attributeType, value)
+		} else {
+			values[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(objectType, values),
+		},
+	}, resp)
+	return resp
+}
+
+func TestProviderConfigureDerivesEndpointFromRegion(t *testing.T) {
+	for _, env := range []string{"VISIONONE_ENDPOINT", "VISIONONE_ENDPOINT_TYPE", "VISIONONE_REGION"} {
+		t.Setenv(env, "")
+	}
+
+	tests := []struct {
+		name        string
+		attributes  map[string]string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:       "derived from region",
+			attributes: map[string]string{"visionone_region": "eu"},
+			expected:   "https://api.eu.xdr.trendmicro.com",
+		},
+		{
+			name:       "endpoint override",
+			attributes: map[string]string{"visionone_region": "dev", "visionone_endpoint": "https://api.dev.example.com"},
+			expected:   "https://api.dev.example.com",
+		},
+		{
+			name:        "unknown region",
+			attributes:  map[string]string{"visionone_region": "ca"},
+			expectedErr: "Invalid VisionOne Region",
+		},
+		{
+			name:        "express without endpoint",
+			attributes:  map[string]string{"visionone_region": "us", "visionone_endpoint_type": "express"},
+			expectedErr: "Missing VisionOne Endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.attributes["visionone_business_id"] = "test-business-id"
+			tt.attributes["visionone_api_key"] = "test-api-key"
+			resp := configureTestProvider(context.Background(), t, tt.attributes)
+
+			if tt.expectedErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectedErr {
+					t.Fatalf("expected %q error, got %v", tt.expectedErr, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			clients := resp.ResourceData.(*aliCloudSecurityProviderClients)
+			if endpoint := *clients.visiononeClients.Cam.Config.Endpoint; endpoint != tt.expected {
+				t.Errorf("expected endpoint %s, got %s", tt.expected, endpoint)
+			}
+			if endpointType := *clients.visiononeClients.Cam.Config.EndpointType; endpointType != "automation" {
+				t.Errorf("expected automation endpoint type, got %s", endpointType)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureRedactsApiKeyFromLogs(t *testing.T) {
+	for _, env := range []string{"VISIONONE_ENDPOINT", "VISIONONE_ENDPOINT_TYPE", "VISIONONE_REGION"} {
+		t.Setenv(env, "")
+	}
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	resp := configureTestProvider(ctx, t, map[string]string{
+		"visionone_region":      "us",
+		"visionone_business_id": "test-business-id",
+		"visionone_api_key":     "secret-api-key",
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	if !strings.Contains(output.String(), "Creating VisionOne API client") {
+		t.Fatalf("expected the provider configuration to be logged, got:\n%s", output.String())
+	}
+	if strings.Contains(output.String(), "secret-api-key") {
+		t.Errorf("expected the API key to be redacted from the logs:\n%s", output.String())
+	}
+}
+
+func TestProviderSchemaMarksApiKeySensitive(t *testing.T) {
+	resp := &provider.SchemaResponse{}
+	New("test")().Schema(context.Background(), provider.SchemaRequest{}, resp)
+
+	if !resp.Schema.Attributes["visionone_api_key"].IsSensitive() {
+		t.Error("expected visionone_api_key to be sensitive")
+	}
+}
+
+func TestProviderConfigureTransport(t *testing.T) {
+	for _, env := range []string{"VISIONONE_ENDPOINT", "VISIONONE_ENDPOINT_TYPE", "VISIONONE_REGION"} {
+		t.Setenv(env, "")
+	}
+
+	tests := []struct {
+		name        string
+		attributes  map[string]string
+		expectedErr string
+	}{
+		{
+			name:       "proxy",
+			attributes: map[string]string{"http_proxy": "http://proxy.example.com:3128"},
+		},
+		{
+			name:        "invalid proxy",
+			attributes:  map[string]string{"http_proxy": "proxy.example.com:3128"},
+			expectedErr: "Invalid HTTP Transport Configuration",
+		},
+		{
+			name:        "missing CA bundle file",
+			attributes:  map[string]string{"ca_bundle_file": t.TempDir() + "/missing.pem"},
+			expectedErr: "Unreadable PEM File",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.attributes["visionone_region"] = "us"
+			tt.attributes["visionone_business_id"] = "test-business-id"
+			tt.attributes["visionone_api_key"] = "test-api-key"
+			resp := configureTestProvider(context.Background(), t, tt.attributes)
+
+			if tt.expectedErr != "" {
+				if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != tt.expectedErr {
+					t.Fatalf("expected %q error, got %v", tt.expectedErr, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			clients := resp.ResourceData.(*aliCloudSecurityProviderClients)
+			if clients.visiononeClients.Transport.HttpProxy != tt.attributes["http_proxy"] || clients.alicloudClients.Transport != clients.visiononeClients.Transport {
+				t.Errorf("expected the VisionOne and AliCloud clients to share the transport configuration, got %+v and %+v",
+					clients.visiononeClients.Transport, clients.alicloudClients.Transport)
+			}
+		})
+	}
+}
+
+func TestBuildTransportConfigRejectsUnknownValues(t *testing.T) {
+	_, diags := buildTransportConfig(aliCloudSecurityProviderModel{
+		HttpProxy: types.StringUnknown(),
+		CaBundle:  types.StringValue(""),
+	})
+	if !diags.HasError() || diags.Errors()[0].Summary() != "Unknown HTTP Transport Setting" {
+		t.Fatalf("expected an unknown setting error, got %v", diags)
+	}
+	if len(diags.Errors()) != 1 {
+		t.Errorf("expected a single error for http_proxy, got %v", diags)
+	}
+}
+
+// newTestProviderConfig returns the provider configuration with the given attributes, the
+// others are null.
+func newTestProviderConfig(ctx context.Context, t *testing.T, attributes map[string]any) tfsdk.Config {
+	t.Helper()
+
+	schemaResp := &provider.SchemaResponse{}
+	New("test")().Schema(ctx, provider.SchemaRequest{}, schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, attributes[name])
+	}
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objectType, values),
+	}
+}
+
+func TestProviderValidateRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		attributes  map[string]any
+		expectedErr bool
+	}{
+		{
+			name:       "defaults",
+			attributes: map[string]any{},
+		},
+		{
+			name:       "min below max",
+			attributes: map[string]any{"retry_min_backoff": int64(2), "retry_max_backoff": int64(10)},
+		},
+		{
+			name:        "min above max",
+			attributes:  map[string]any{"retry_min_backoff": int64(10), "retry_max_backoff": int64(2)},
+			expectedErr: true,
+		},
+		{
+			name:        "min above default max",
+			attributes:  map[string]any{"retry_min_backoff": int64(60)},
+			expectedErr: true,
+		},
+		{
+			name:       "unknown max",
+			attributes: map[string]any{"retry_min_backoff": int64(60), "retry_max_backoff": tftypes.UnknownValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			resp := &provider.ValidateConfigResponse{}
+			retryBackoffValidator{}.ValidateProvider(ctx, provider.ValidateConfigRequest{
+				Config: newTestProviderConfig(ctx, t, tt.attributes),
+			}, resp)
+
+			if resp.Diagnostics.HasError() != tt.expectedErr {
+				t.Fatalf("expected error %t, got %v", tt.expectedErr, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestAccProviderInvalidRetries(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "alicloudsecurity" {
+  visionone_business_id = "test-business-id"
+  visionone_api_key     = "test-api-key"
+  visionone_region      = "us"
+  max_retries           = -1
+}
+
+data "alicloudsecurity_connected_accounts" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`value must be at least 0`),
+			},
+		},
+	})
+}
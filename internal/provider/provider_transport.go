@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"os"
+	"terraform-provider-alicloudsecurity/internal/common"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// transportProviderAttributes returns the provider attributes configuring the HTTP transport
+// of the VisionOne and AliCloud clients.
+func transportProviderAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"request_timeout": schema.Int64Attribute{
+			Description: "Timeout in seconds of an attempt of a VisionOne or AliCloud API request, reading the response included. Defaults to 60.",
+			Optional:    true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+		},
+		"http_proxy": schema.StringAttribute{
+			Description: "URL of the proxy of the VisionOne and AliCloud API requests, such as http://proxy.example.com:3128. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+			Optional:    true,
+		},
+		"ca_bundle_file": schema.StringAttribute{
+			Description: "Path of a PEM file of CA certificates trusted in addition to the system ones, such as the CA of a corporate TLS inspection proxy. Conflicts with ca_bundle.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRoot("ca_bundle")),
+			},
+		},
+		"ca_bundle": schema.StringAttribute{
+			Description: "PEM encoded CA certificates trusted in addition to the system ones. Conflicts with ca_bundle_file.",
+			Optional:    true,
+		},
+		"client_certificate_file": schema.StringAttribute{
+			Description: "Path of a PEM file of the client certificate presented to the servers requesting mutual TLS. Requires client_key_file. " +
+				"Unlike the CA certificates, the client certificate and its key can only be read from files, so that the private key is not written in the configuration.",
+			Optional: true,
+			Validators: []validator.String{
+				stringvalidator.AlsoRequires(path.MatchRoot("client_key_file")),
+			},
+		},
+		"client_key_file": schema.StringAttribute{
+			Description: "Path of a PEM file of the private key of the client certificate. Requires client_certificate_file.",
+			Optional:    true,
+			Validators: []validator.String{
+				stringvalidator.AlsoRequires(path.MatchRoot("client_certificate_file")),
+			},
+		},
+		"insecure_skip_verify": schema.BoolAttribute{
+			Description: "Skip the verification of the VisionOne server certificates. Only use it with development stacks. Defaults to false.",
+			Optional:    true,
+		},
+	}
+}
+
+// buildTransportConfig builds the configuration of the HTTP transport from the provider
+// configuration, reading the configured PEM files.
+func buildTransportConfig(model aliCloudSecurityProviderModel) (*common.TransportConfig, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// An unknown value would silently configure the transport without the setting
+	for _, attribute := range []struct {
+		name  string
+		value attr.Value
+	}{
+		{"request_timeout", model.RequestTimeout},
+		{"http_proxy", model.HttpProxy},
+		{"ca_bundle_file", model.CaBundleFile},
+		{"ca_bundle", model.CaBundle},
+		{"client_certificate_file", model.ClientCertificateFile},
+		{"client_key_file", model.ClientKeyFile},
+		{"insecure_skip_verify", model.InsecureSkipVerify},
+	} {
+		if attribute.value.IsUnknown() {
+			diags.AddAttributeError(
+				path.Root(attribute.name),
+				"Unknown HTTP Transport Setting",
+				"The provider cannot configure the HTTP transport of the API clients as there is an unknown configuration value for "+attribute.name+". "+
+					"Either target apply the source of the value first or set the value statically in the configuration.",
+			)
+		}
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	config := &common.TransportConfig{
+		HttpProxy:          model.HttpProxy.ValueString(),
+		CaBundle:           model.CaBundle.ValueString(),
+		InsecureSkipVerify: model.InsecureSkipVerify.ValueBool(),
+	}
+	if !model.RequestTimeout.IsNull() {
+		config.Timeout = time.Duration(model.RequestTimeout.ValueInt64()) * time.Second
+	}
+
+	readPemFile(&config.CaBundle, model.CaBundleFile, path.Root("ca_bundle_file"), &diags)
+	readPemFile(&config.ClientCertificate, model.ClientCertificateFile, path.Root("client_certificate_file"), &diags)
+	readPemFile(&config.ClientKey, model.ClientKeyFile, path.Root("client_key_file"), &diags)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	// Report the invalid proxy URLs and PEM contents before the clients are built
+	if _, err := common.NewHTTPTransport(config); err != nil {
+		diags.AddError(
+			"Invalid HTTP Transport Configuration",
+			"The provider cannot configure the HTTP transport of the API clients: "+err.Error()+". "+
+				"Fix the http_proxy, ca_bundle, ca_bundle_file, client_certificate_file or client_key_file value.",
+		)
+		return nil, diags
+	}
+
+	return config, diags
+}
+
+// readPemFile sets target to the content of the configured file, if any.
+func readPemFile(target *string, filePath types.String, attributePath path.Path, diags *diag.Diagnostics) {
+	if filePath.IsNull() || filePath.IsUnknown() {
+		return
+	}
+	data, err := os.ReadFile(filePath.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"Unreadable PEM File",
+			"The provider cannot read the PEM file: "+err.Error(),
+		)
+		return
+	}
+	*target = string(data)
+}
@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-alicloudsecurity/internal/common"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// accountIdValidators validate the ID of an AliCloud Account, sent as the accountId of a
+// CreateConnectionRequest.
+func accountIdValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.LengthBetween(1, 16),
+		stringvalidator.RegexMatches(regexp.MustCompile(`^\d+$`), "must only contain digits"),
+	}
+}
+
+// roleArnValidators validate the ARN of a RAM role, sent as the roleArn of a
+// CreateConnectionRequest.
+func roleArnValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.LengthAtMost(254),
+		roleArnValidator{},
+	}
+}
+
+// roleArnValidator validates the ARN of a RAM role, parsed as the resources parse it.
+type roleArnValidator struct{}
+
+var _ validator.String = roleArnValidator{}
+
+// Description describes the validation in plain text formatting.
+func (v roleArnValidator) Description(_ context.Context) string {
+	return "must be a RAM role ARN like acs:ram::<account>:role/<name>"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v roleArnValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation, skipped until the value is known.
+func (v roleArnValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, _, err := common.ParseRoleArn(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Role ARN",
+			fmt.Sprintf("%s %s: %v.", req.Path, v.Description(ctx), err),
+		)
+	}
+}
+
+// aliCloudRegionValidators validate the ID of a public region of Alibaba Cloud.
+func aliCloudRegionValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.OneOf(common.AliCloudRegions...),
+	}
+}
+
+// roleArnAccountValidator checks that the role ARN belongs to the account of the resource, as
+// VisionOne cannot assume a role of another account to manage it.
+type roleArnAccountValidator struct {
+	accountIdPath path.Path
+	roleArnPath   path.Path
+}
+
+var _ resource.ConfigValidator = roleArnAccountValidator{}
+
+// Description describes the validation in plain text formatting.
+func (v roleArnAccountValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("%s must be a role of the account %s", v.roleArnPath, v.accountIdPath)
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v roleArnAccountValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateResource performs the validation, skipped until both values are known.
+func (v roleArnAccountValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var accountId, roleArn types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, v.accountIdPath, &accountId)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, v.roleArnPath, &roleArn)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if accountId.IsNull() || accountId.IsUnknown() || roleArn.IsNull() || roleArn.IsUnknown() {
+		return
+	}
+
+	roleAccountId, _, err := common.ParseRoleArn(roleArn.ValueString())
+	if err != nil {
+		// Reported by the validators of the attribute
+		return
+	}
+	if roleAccountId != accountId.ValueString() {
+		resp.Diagnostics.AddAttributeError(
+			v.roleArnPath,
+			"Role ARN Account Mismatch",
+			fmt.Sprintf("The role %s belongs to the account %s, but the account_id is %s. "+
+				"VisionOne can only connect an AliCloud Account with a role of the same account.",
+				roleArn.ValueString(), roleAccountId, accountId.ValueString()),
+		)
+	}
+}
+
+// durationValidator validates a positive duration like 24h or 90m, as parsed by
+// time.ParseDuration.
+type durationValidator struct{}
+
+var _ validator.String = durationValidator{}
+
+// Description describes the validation in plain text formatting.
+func (v durationValidator) Description(_ context.Context) string {
+	return "must be a positive duration like 24h or 90m"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString performs the validation, skipped until the value is known.
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil || duration <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("%s %s, got %q.", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// retryBackoffValidator checks that the minimum backoff of the retries of the provider does
+// not exceed their maximum backoff, skipped until both values are known.
+type retryBackoffValidator struct{}
+
+var _ provider.ConfigValidator = retryBackoffValidator{}
+
+// Description describes the validation in plain text formatting.
+func (v retryBackoffValidator) Description(_ context.Context) string {
+	return "retry_min_backoff must be less than or equal to retry_max_backoff"
+}
+
+// MarkdownDescription describes the validation in Markdown formatting.
+func (v retryBackoffValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateProvider performs the validation, comparing an unset backoff by its default.
+func (v retryBackoffValidator) ValidateProvider(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var minBackoff, maxBackoff types.Int64
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("retry_min_backoff"), &minBackoff)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("retry_max_backoff"), &maxBackoff)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if minBackoff.IsUnknown() || maxBackoff.IsUnknown() {
+		return
+	}
+
+	minSeconds := int64(common.DefaultMinBackoff / time.Second)
+	if !minBackoff.IsNull() {
+		minSeconds = minBackoff.ValueInt64()
+	}
+	maxSeconds := int64(common.DefaultMaxBackoff / time.Second)
+	if !maxBackoff.IsNull() {
+		maxSeconds = maxBackoff.ValueInt64()
+	}
+	if minSeconds > maxSeconds {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_min_backoff"),
+			"Invalid Retry Backoff",
+			fmt.Sprintf("The retry_min_backoff value (%d) must be less than or equal to the retry_max_backoff value (%d).", minSeconds, maxSeconds),
+		)
+	}
+}
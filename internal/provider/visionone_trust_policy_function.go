@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &visionOneTrustPolicyFunction{}
+
+// NewVisionOneTrustPolicyFunction is a helper function to simplify the provider implementation.
+func NewVisionOneTrustPolicyFunction() function.Function {
+	return &visionOneTrustPolicyFunction{}
+}
+
+// visionOneTrustPolicyFunction renders the trust policy of the RAM role assumed by VisionOne.
+type visionOneTrustPolicyFunction struct{}
+
+// Metadata returns the function name.
+func (f *visionOneTrustPolicyFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "visionone_trust_policy"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *visionOneTrustPolicyFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Render the trust policy of the RAM role assumed by VisionOne",
+		Description: "Given the ID of an AliCloud account, the name of its OIDC provider trusting VisionOne, the VisionOne business ID and " +
+			"the VisionOne region, return the canonical trust policy JSON of the RAM role assumed by VisionOne. The role trusts the OIDC " +
+			"provider for the tokens issued by the VisionOne region to the business only. It is the trust policy written by the " +
+			"alicloudsecurity_visionone_trust resource.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "account_id",
+				Description: "The ID of the AliCloud account owning the role.",
+			},
+			function.StringParameter{
+				Name:        "oidc_provider_id",
+				Description: "The name of the OIDC identity provider trusting VisionOne in the account.",
+			},
+			function.StringParameter{
+				Name:        "business_id",
+				Description: "The VisionOne business ID, the audience of the OIDC tokens.",
+			},
+			function.StringParameter{
+				Name:        "region",
+				Description: "The VisionOne region issuing the OIDC tokens, such as us or eu.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run renders the trust policy.
+func (f *visionOneTrustPolicyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var accountId, oidcProviderId, businessId, region string
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &accountId, &oidcProviderId, &businessId, &region))
+	if resp.Error != nil {
+		return
+	}
+
+	if err := common.ValidateAccountId(accountId); err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	if err := common.ValidateOidcProviderName(oidcProviderId); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+	if businessId == "" {
+		resp.Error = function.NewArgumentFuncError(2, "the VisionOne business ID cannot be empty")
+		return
+	}
+	if region == "" {
+		resp.Error = function.NewArgumentFuncError(3, "the VisionOne region cannot be empty")
+		return
+	}
+	if regions := common.VisionOneRegions(); !slices.Contains(regions, region) {
+		resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("%q is not a VisionOne region, expected one of %s", region, strings.Join(regions, ", ")))
+		return
+	}
+
+	policy, err := common.BuildVisionOneTrustPolicy(accountId, oidcProviderId, businessId, region)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, policy))
+}
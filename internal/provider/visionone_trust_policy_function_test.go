@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestVisionOneTrustPolicyFunction(t *testing.T) {
+	// The fixture pinning the canonical trust policy is shared with the common package
+	document, err := os.ReadFile("../common/testdata/visionone_trust_policy.json")
+	if err != nil {
+		t.Fatalf("failed to read the trust policy fixture: %v", err)
+	}
+	var expected bytes.Buffer
+	if err := json.Compact(&expected, document); err != nil {
+		t.Fatalf("failed to compact the trust policy fixture: %v", err)
+	}
+
+	result, errText := callTestFunction(t, "visionone_trust_policy", tftypes.String, "1234567890123456", "trendmicro-visionone", "2a1b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d", "us")
+	if errText != "" {
+		t.Fatalf("unexpected error: %s", errText)
+	}
+
+	var policy string
+	if err := result.As(&policy); err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	if policy != expected.String() {
+		t.Errorf("unexpected trust policy:\n got: %s\nwant: %s", policy, expected.String())
+	}
+}
+
+func TestVisionOneTrustPolicyFunctionInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "account id",
+			args:        []string{"account", "trendmicro-visionone", "business-id", "us"},
+			expectedErr: "is not an AliCloud account ID",
+		},
+		{
+			name:        "oidc provider id",
+			args:        []string{"1234567890123456", "trendmicro/visionone", "business-id", "us"},
+			expectedErr: "is not an OIDC provider name",
+		},
+		{
+			name:        "business id",
+			args:        []string{"1234567890123456", "trendmicro-visionone", "", "us"},
+			expectedErr: "business ID cannot be empty",
+		},
+		{
+			name:        "region",
+			args:        []string{"1234567890123456", "trendmicro-visionone", "business-id", ""},
+			expectedErr: "region cannot be empty",
+		},
+		{
+			name:        "unknown region",
+			args:        []string{"1234567890123456", "trendmicro-visionone", "business-id", "us-east-1"},
+			expectedErr: `"us-east-1" is not a VisionOne region`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, errText := callTestFunction(t, "visionone_trust_policy", tftypes.String, tt.args...); !strings.Contains(errText, tt.expectedErr) {
+				t.Errorf("expected %q error, got %q", tt.expectedErr, errText)
+			}
+		})
+	}
+}
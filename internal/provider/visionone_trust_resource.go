@@ -0,0 +1,695 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	ram "github.com/alibabacloud-go/ram-20150501/v2/client"
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &visionOneTrustResource{}
+	_ resource.ResourceWithConfigure   = &visionOneTrustResource{}
+	_ resource.ResourceWithImportState = &visionOneTrustResource{}
+	_ resource.ResourceWithModifyPlan  = &visionOneTrustResource{}
+)
+
+const (
+	policyTypeSystem = "System"
+	policyTypeCustom = "Custom"
+)
+
+// NewVisionOneTrustResource is a helper function to simplify the provider implementation.
+func NewVisionOneTrustResource() resource.Resource {
+	return &visionOneTrustResource{}
+}
+
+// visionOneTrustResource is the resource implementation.
+type visionOneTrustResource struct {
+	cam      *common.CamClient
+	alicloud *common.AliCloudClients
+}
+
+// visionOneTrustResourceModel maps the resource schema.
+type visionOneTrustResourceModel struct {
+	OidcProviderName   types.String `tfsdk:"oidc_provider_name"`   // The name of the OIDC provider trusted by the role.
+	OidcIssuerUrl      types.String `tfsdk:"oidc_issuer_url"`      // The issuer URL of the VisionOne OIDC tokens.
+	OidcFingerprints   types.Set    `tfsdk:"oidc_fingerprints"`    // The fingerprints of the certificate of the issuer. *required*
+	OidcClientIds      types.Set    `tfsdk:"oidc_client_ids"`      // The client IDs (audiences) of the OIDC provider.
+	RoleName           types.String `tfsdk:"role_name"`            // The name of the role assumed by VisionOne.
+	RoleDescription    types.String `tfsdk:"role_description"`     // The description of the role assumed by VisionOne.
+	MaxSessionDuration types.Int64  `tfsdk:"max_session_duration"` // The maximum session duration of the role in seconds.
+	SystemPolicyNames  types.Set    `tfsdk:"system_policy_names"`  // The system policies attached to the role.
+	CustomPolicyNames  types.Set    `tfsdk:"custom_policy_names"`  // The custom policies attached to the role.
+
+	AccountId       types.String `tfsdk:"account_id"`        // The ID of the AliCloud Account.
+	RoleArn         types.String `tfsdk:"role_arn"`          // The ARN of the role assumed by VisionOne.
+	OidcProviderId  types.String `tfsdk:"oidc_provider_id"`  // The ID of the OIDC provider, as expected by the connected account.
+	OidcProviderArn types.String `tfsdk:"oidc_provider_arn"` // The ARN of the OIDC provider.
+	TrustPolicy     types.String `tfsdk:"trust_policy"`      // The trust policy document of the role.
+}
+
+// Metadata returns the resource type name.
+func (r *visionOneTrustResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_visionone_trust"
+}
+
+// Schema defines the schema for the resource.
+func (r *visionOneTrustResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The OIDC provider and the RAM role that allow VisionOne to access the AliCloud Account. " +
+			"The role_arn and oidc_provider_id attributes are the inputs of the alicloudsecurity_connected_account resource.",
+		Attributes: map[string]schema.Attribute{
+			"oidc_provider_name": schema.StringAttribute{
+				Description: "The name of the OIDC provider trusted by the role. Changing this forces a new trust.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("trendmicro-visionone"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"oidc_issuer_url": schema.StringAttribute{
+				Description: "The issuer URL of the VisionOne OIDC tokens, trusted by the OIDC provider and by the trust policy of the role. " +
+					"Defaults to the issuer of the VisionOne region of the provider. Changing this forces a new trust.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"oidc_fingerprints": schema.SetAttribute{
+				Description: "The SHA-1 fingerprints of the certificate of the OIDC issuer. *required*",
+				Required:    true,
+				ElementType: types.StringType,
+			},
+			"oidc_client_ids": schema.SetAttribute{
+				Description: "The client IDs (audiences) of the OIDC provider, also accepted by the trust policy of the role. " +
+					"Defaults to the VisionOne business ID of the provider.",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_name": schema.StringAttribute{
+				Description: "The name of the RAM role assumed by VisionOne. Changing this forces a new trust.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("trendmicro-visionone-role"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role_description": schema.StringAttribute{
+				Description: "The description of the RAM role assumed by VisionOne.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("Role assumed by Trend Vision One Cloud Account Management."),
+			},
+			"max_session_duration": schema.Int64Attribute{
+				Description: "The maximum session duration of the RAM role in seconds. Defaults to 3600.",
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(3600),
+			},
+			"system_policy_names": schema.SetAttribute{
+				Description: "The system policies attached to the RAM role. Defaults to ReadOnlyAccess.",
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Default: setdefault.StaticValue(types.SetValueMust(types.StringType, []attr.Value{
+					types.StringValue("ReadOnlyAccess"),
+				})),
+			},
+			"custom_policy_names": schema.SetAttribute{
+				Description: "The custom policies attached to the RAM role.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"account_id": schema.StringAttribute{
+				Description: "The ID of the AliCloud Account.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_arn": schema.StringAttribute{
+				Description: "The ARN of the RAM role assumed by VisionOne.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"oidc_provider_id": schema.StringAttribute{
+				Description: "The ID of the OIDC provider, as expected by the alicloudsecurity_connected_account resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"oidc_provider_arn": schema.StringAttribute{
+				Description: "The ARN of the OIDC provider.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"trust_policy": schema.StringAttribute{
+				Description: "The trust policy document of the RAM role.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure prepares the provider for resource operations.
+func (r *visionOneTrustResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	clients := req.ProviderData.(*aliCloudSecurityProviderClients)
+	if clients == nil {
+		resp.Diagnostics.AddError(
+			"Client Error",
+			"Client configuration is not set up properly. Please configure the provider.",
+		)
+		return
+	}
+	r.cam = clients.visiononeClients.Cam
+	r.alicloud = clients.alicloudClients
+}
+
+// ModifyPlan plans the OIDC settings that are not configured from the VisionOne region and
+// business of the provider, so that a change of the provider reaches the OIDC provider and the
+// trust policy of the role.
+func (r *visionOneTrustResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy, or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.cam == nil {
+		return
+	}
+
+	var issuerUrl types.String
+	var clientIds types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("oidc_issuer_url"), &issuerUrl)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("oidc_client_ids"), &clientIds)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if issuerUrl.IsNull() {
+		defaultIssuerUrl := types.StringValue(common.VisionOneOidcIssuerUrl(*r.cam.Config.Region))
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("oidc_issuer_url"), defaultIssuerUrl)...)
+		if !req.State.Raw.IsNull() {
+			var stateIssuerUrl types.String
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("oidc_issuer_url"), &stateIssuerUrl)...)
+			// The plan modifiers of the attribute ran before the default was planned
+			if !stateIssuerUrl.Equal(defaultIssuerUrl) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("oidc_issuer_url"))
+			}
+		}
+	}
+	if clientIds.IsNull() {
+		defaultClientIds := stringsToSet(ctx, []string{*r.cam.Config.BusinessId}, &resp.Diagnostics)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("oidc_client_ids"), defaultClientIds)...)
+	}
+}
+
+// Create creates the resource and sets the initial state.
+func (r *visionOneTrustResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve the values from plan
+	var plan visionOneTrustResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	alicloud, err := r.alicloud.Build()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create AliCloud API client",
+			"Unable to create AliCloud API client: "+err.Error(),
+		)
+		return
+	}
+	accountId, err := alicloud.CallerAccountId()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Get Caller Identity Error",
+			"Failed to get the AliCloud Account of the credentials: "+err.Error(),
+		)
+		return
+	}
+
+	// Default the OIDC settings to the VisionOne region and business of the provider
+	if plan.OidcIssuerUrl.IsUnknown() {
+		plan.OidcIssuerUrl = types.StringValue(common.VisionOneOidcIssuerUrl(*r.cam.Config.Region))
+	}
+	if plan.OidcClientIds.IsUnknown() {
+		plan.OidcClientIds, diags = types.SetValueFrom(ctx, types.StringType, []string{*r.cam.Config.BusinessId})
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Create the OIDC provider
+	oidcProviderName := plan.OidcProviderName.ValueString()
+	oidcProvider, err := alicloud.Ims.CreateOidcProvider(&common.CreateOidcProviderRequest{
+		OIDCProviderName: oidcProviderName,
+		IssuerUrl:        plan.OidcIssuerUrl.ValueString(),
+		Fingerprints:     setToStrings(ctx, plan.OidcFingerprints, &resp.Diagnostics),
+		ClientIds:        setToStrings(ctx, plan.OidcClientIds, &resp.Diagnostics),
+		Description:      "OIDC provider of Trend Vision One Cloud Account Management.",
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Create OIDC Provider Error",
+			"Failed to create OIDC provider: "+err.Error(),
+		)
+		return
+	}
+
+	// Create the role trusting the OIDC provider
+	trustPolicy, err := common.BuildOidcTrustPolicy(accountId, oidcProviderName, plan.OidcIssuerUrl.ValueString(),
+		setToStrings(ctx, plan.OidcClientIds, &resp.Diagnostics))
+	if err == nil {
+		var createRoleResp *ram.CreateRoleResponse
+		createRoleResp, err = alicloud.Ram.CreateRole(&ram.CreateRoleRequest{
+			RoleName:                 plan.RoleName.ValueStringPointer(),
+			AssumeRolePolicyDocument: tea.String(trustPolicy),
+			Description:              plan.RoleDescription.ValueStringPointer(),
+			MaxSessionDuration:       plan.MaxSessionDuration.ValueInt64Pointer(),
+		})
+		if err == nil {
+			plan.RoleArn = types.StringValue(tea.StringValue(createRoleResp.Body.Role.Arn))
+		}
+	}
+	if err != nil {
+		// Do not leave an OIDC provider behind that Terraform does not track
+		if deleteErr := alicloud.Ims.DeleteOidcProvider(oidcProviderName); deleteErr != nil {
+			common.LogWarn(ctx, "Failed to delete OIDC provider after role creation failure", map[string]any{
+				"oidc_provider_name": oidcProviderName,
+				"error":              deleteErr.Error(),
+			})
+		}
+		resp.Diagnostics.AddError(
+			"Create Role Error",
+			"Failed to create role: "+err.Error(),
+		)
+		return
+	}
+
+	plan.AccountId = types.StringValue(accountId)
+	plan.OidcProviderId = types.StringValue(oidcProviderName)
+	plan.OidcProviderArn = types.StringValue(oidcProvider.Arn)
+	plan.TrustPolicy = types.StringValue(trustPolicy)
+
+	// Set state before attaching the policies, so that a failed attachment taints the resource
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.updatePolicyAttachments(ctx, alicloud.Ram, plan.RoleName.ValueString(), nil, nil,
+		setToStrings(ctx, plan.SystemPolicyNames, &resp.Diagnostics),
+		setToStrings(ctx, plan.CustomPolicyNames, &resp.Diagnostics),
+		&resp.Diagnostics)
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *visionOneTrustResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get the current state
+	var state visionOneTrustResourceModel
+	diags := req.State.Get(ctx, &state)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	alicloud, err := r.alicloud.Build()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create AliCloud API client",
+			"Unable to create AliCloud API client: "+err.Error(),
+		)
+		return
+	}
+
+	// Get refreshed data from the API
+	oidcProvider, err := alicloud.Ims.GetOidcProvider(state.OidcProviderId.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read OIDC Provider Error",
+			"Failed to read OIDC provider: "+err.Error(),
+		)
+		return
+	}
+	getRoleResp, err := alicloud.Ram.GetRole(&ram.GetRoleRequest{RoleName: state.RoleName.ValueStringPointer()})
+	if err != nil && !common.IsAliCloudNotFoundError(err) {
+		resp.Diagnostics.AddError(
+			"Read Role Error",
+			"Failed to read role: "+err.Error(),
+		)
+		return
+	}
+	if oidcProvider == nil || err != nil {
+		// The trust was deleted outside of Terraform, remove it from the state
+		// so that Terraform plans to recreate it.
+		common.LogWarn(ctx, "VisionOne trust not found, removing from state", map[string]any{
+			"role_name":          state.RoleName.ValueString(),
+			"oidc_provider_name": state.OidcProviderId.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Overwrite the state with the read response
+	role := getRoleResp.Body.Role
+	accountId, _, err := common.ParseRoleArn(tea.StringValue(role.Arn))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read Role Error",
+			"Failed to read the account of the role: "+err.Error(),
+		)
+		return
+	}
+	state.AccountId = types.StringValue(accountId)
+	state.OidcProviderName = types.StringValue(oidcProvider.OIDCProviderName)
+	state.OidcProviderId = types.StringValue(oidcProvider.OIDCProviderName)
+	state.OidcProviderArn = types.StringValue(oidcProvider.Arn)
+	state.OidcIssuerUrl = types.StringValue(oidcProvider.IssuerUrl)
+	state.OidcFingerprints = stringsToSet(ctx, oidcProvider.Fingerprints, &resp.Diagnostics)
+	state.OidcClientIds = stringsToSet(ctx, oidcProvider.ClientIds, &resp.Diagnostics)
+	state.RoleName = types.StringValue(tea.StringValue(role.RoleName))
+	state.RoleArn = types.StringValue(tea.StringValue(role.Arn))
+	state.RoleDescription = types.StringValue(tea.StringValue(role.Description))
+	state.MaxSessionDuration = types.Int64Value(tea.Int64Value(role.MaxSessionDuration))
+	state.TrustPolicy = types.StringValue(tea.StringValue(role.AssumeRolePolicyDocument))
+
+	systemPolicyNames, customPolicyNames, err := listRolePolicyNames(alicloud.Ram, state.RoleName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read Role Policies Error",
+			"Failed to list the policies of the role: "+err.Error(),
+		)
+		return
+	}
+	state.SystemPolicyNames = stringsToSet(ctx, systemPolicyNames, &resp.Diagnostics)
+	if len(customPolicyNames) > 0 || !state.CustomPolicyNames.IsNull() {
+		state.CustomPolicyNames = stringsToSet(ctx, customPolicyNames, &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update modifies the existing resource and sets the updated state.
+func (r *visionOneTrustResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve the values from plan and state
+	var plan, state visionOneTrustResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alicloud, err := r.alicloud.Build()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create AliCloud API client",
+			"Unable to create AliCloud API client: "+err.Error(),
+		)
+		return
+	}
+	oidcProviderName := state.OidcProviderId.ValueString()
+
+	// Update the OIDC provider
+	if !plan.OidcClientIds.Equal(state.OidcClientIds) {
+		if _, err := alicloud.Ims.UpdateOidcProviderClientIds(oidcProviderName, setToStrings(ctx, plan.OidcClientIds, &resp.Diagnostics)); err != nil {
+			resp.Diagnostics.AddError(
+				"Update OIDC Provider Error",
+				"Failed to update the client IDs of the OIDC provider: "+err.Error(),
+			)
+			return
+		}
+	}
+	plannedFingerprints := setToStrings(ctx, plan.OidcFingerprints, &resp.Diagnostics)
+	currentFingerprints := setToStrings(ctx, state.OidcFingerprints, &resp.Diagnostics)
+	// Add the new fingerprints first, a provider cannot be left without fingerprint
+	for _, fingerprint := range plannedFingerprints {
+		if !slices.Contains(currentFingerprints, fingerprint) {
+			if err := alicloud.Ims.AddOidcProviderFingerprint(oidcProviderName, fingerprint); err != nil {
+				resp.Diagnostics.AddError(
+					"Update OIDC Provider Error",
+					"Failed to add a fingerprint to the OIDC provider: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+	for _, fingerprint := range currentFingerprints {
+		if !slices.Contains(plannedFingerprints, fingerprint) {
+			if err := alicloud.Ims.RemoveOidcProviderFingerprint(oidcProviderName, fingerprint); err != nil {
+				resp.Diagnostics.AddError(
+					"Update OIDC Provider Error",
+					"Failed to remove a fingerprint from the OIDC provider: "+err.Error(),
+				)
+				return
+			}
+		}
+	}
+
+	// Update the role, rewriting the trust policy in case it was changed outside of Terraform
+	// or the client IDs changed
+	trustPolicy, err := common.BuildOidcTrustPolicy(state.AccountId.ValueString(), oidcProviderName, plan.OidcIssuerUrl.ValueString(),
+		setToStrings(ctx, plan.OidcClientIds, &resp.Diagnostics))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Update Role Error",
+			"Failed to build the trust policy: "+err.Error(),
+		)
+		return
+	}
+	_, err = alicloud.Ram.UpdateRole(&ram.UpdateRoleRequest{
+		RoleName:                    state.RoleName.ValueStringPointer(),
+		NewAssumeRolePolicyDocument: tea.String(trustPolicy),
+		NewDescription:              plan.RoleDescription.ValueStringPointer(),
+		NewMaxSessionDuration:       plan.MaxSessionDuration.ValueInt64Pointer(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Update Role Error",
+			"Failed to update role: "+err.Error(),
+		)
+		return
+	}
+	plan.TrustPolicy = types.StringValue(trustPolicy)
+
+	r.updatePolicyAttachments(ctx, alicloud.Ram, state.RoleName.ValueString(),
+		setToStrings(ctx, state.SystemPolicyNames, &resp.Diagnostics),
+		setToStrings(ctx, state.CustomPolicyNames, &resp.Diagnostics),
+		setToStrings(ctx, plan.SystemPolicyNames, &resp.Diagnostics),
+		setToStrings(ctx, plan.CustomPolicyNames, &resp.Diagnostics),
+		&resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated plan
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete removes the resource from the state.
+func (r *visionOneTrustResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve the values from state
+	var state visionOneTrustResourceModel
+	diags := req.State.Get(ctx, &state)
+	if diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	alicloud, err := r.alicloud.Build()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create AliCloud API client",
+			"Unable to create AliCloud API client: "+err.Error(),
+		)
+		return
+	}
+
+	// A role cannot be deleted while policies are attached to it
+	roleName := state.RoleName.ValueString()
+	systemPolicyNames, customPolicyNames, err := listRolePolicyNames(alicloud.Ram, roleName)
+	if err != nil && !common.IsAliCloudNotFoundError(err) {
+		resp.Diagnostics.AddError(
+			"Delete Role Error",
+			"Failed to list the policies of the role: "+err.Error(),
+		)
+		return
+	}
+	r.updatePolicyAttachments(ctx, alicloud.Ram, roleName, systemPolicyNames, customPolicyNames, nil, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err = alicloud.Ram.DeleteRole(&ram.DeleteRoleRequest{RoleName: tea.String(roleName)})
+	if err != nil && !common.IsAliCloudNotFoundError(err) {
+		resp.Diagnostics.AddError(
+			"Delete Role Error",
+			"Failed to delete role: "+err.Error(),
+		)
+		return
+	}
+
+	if err := alicloud.Ims.DeleteOidcProvider(state.OidcProviderId.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Delete OIDC Provider Error",
+			"Failed to delete OIDC provider: "+err.Error(),
+		)
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// ImportState imports the resource state by the names of the role and of the OIDC provider,
+// separated by a colon, like trendmicro-visionone-role:trendmicro-visionone.
+func (r *visionOneTrustResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	roleName, oidcProviderName, ok := strings.Cut(req.ID, ":")
+	if !ok || roleName == "" || oidcProviderName == "" {
+		resp.Diagnostics.AddError(
+			"Import VisionOne Trust Error",
+			fmt.Sprintf("The import ID must be the name of the role and the name of the OIDC provider separated by a colon, "+
+				"like trendmicro-visionone-role:trendmicro-visionone, got %q.", req.ID),
+		)
+		return
+	}
+
+	// The other attributes are set by Read
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role_name"), roleName)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("oidc_provider_id"), oidcProviderName)...)
+}
+
+// updatePolicyAttachments attaches and detaches the policies of the role to go from the current
+// to the planned policies.
+func (r *visionOneTrustResource) updatePolicyAttachments(ctx context.Context, client *ram.Client, roleName string,
+	currentSystem, currentCustom, plannedSystem, plannedCustom []string, diags *diag.Diagnostics) {
+	for policyType, policies := range map[string][2][]string{
+		policyTypeSystem: {currentSystem, plannedSystem},
+		policyTypeCustom: {currentCustom, plannedCustom},
+	} {
+		current, planned := policies[0], policies[1]
+		for _, policyName := range planned {
+			if slices.Contains(current, policyName) {
+				continue
+			}
+			common.LogDebug(ctx, "Attaching policy to role", map[string]any{"role_name": roleName, "policy_name": policyName, "policy_type": policyType})
+			_, err := client.AttachPolicyToRole(&ram.AttachPolicyToRoleRequest{
+				RoleName:   tea.String(roleName),
+				PolicyName: tea.String(policyName),
+				PolicyType: tea.String(policyType),
+			})
+			if err != nil {
+				diags.AddError(
+					"Attach Policy Error",
+					fmt.Sprintf("Failed to attach %s policy %s to role %s: %s", policyType, policyName, roleName, err.Error()),
+				)
+				return
+			}
+		}
+		for _, policyName := range current {
+			if slices.Contains(planned, policyName) {
+				continue
+			}
+			common.LogDebug(ctx, "Detaching policy from role", map[string]any{"role_name": roleName, "policy_name": policyName, "policy_type": policyType})
+			_, err := client.DetachPolicyFromRole(&ram.DetachPolicyFromRoleRequest{
+				RoleName:   tea.String(roleName),
+				PolicyName: tea.String(policyName),
+				PolicyType: tea.String(policyType),
+			})
+			if err != nil && !common.IsAliCloudNotFoundError(err) {
+				diags.AddError(
+					"Detach Policy Error",
+					fmt.Sprintf("Failed to detach %s policy %s from role %s: %s", policyType, policyName, roleName, err.Error()),
+				)
+				return
+			}
+		}
+	}
+}
+
+// listRolePolicyNames returns the names of the system and custom policies attached to the role.
+func listRolePolicyNames(client *ram.Client, roleName string) ([]string, []string, error) {
+	resp, err := client.ListPoliciesForRole(&ram.ListPoliciesForRoleRequest{RoleName: tea.String(roleName)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	systemPolicyNames := []string{}
+	customPolicyNames := []string{}
+	if resp.Body != nil && resp.Body.Policies != nil {
+		for _, policy := range resp.Body.Policies.Policy {
+			switch tea.StringValue(policy.PolicyType) {
+			case policyTypeSystem:
+				systemPolicyNames = append(systemPolicyNames, tea.StringValue(policy.PolicyName))
+			case policyTypeCustom:
+				customPolicyNames = append(customPolicyNames, tea.StringValue(policy.PolicyName))
+			}
+		}
+	}
+	return systemPolicyNames, customPolicyNames, nil
+}
+
+// setToStrings converts a set of strings to a slice, a null or unknown set is an empty slice.
+func setToStrings(ctx context.Context, set types.Set, diags *diag.Diagnostics) []string {
+	values := []string{}
+	if set.IsNull() || set.IsUnknown() {
+		return values
+	}
+	diags.Append(set.ElementsAs(ctx, &values, false)...)
+	return values
+}
+
+// stringsToSet converts a slice of strings to a set.
+func stringsToSet(ctx context.Context, values []string, diags *diag.Diagnostics) types.Set {
+	if values == nil {
+		values = []string{}
+	}
+	set, d := types.SetValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return set
+}
@@ -0,0 +1,162 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// testAccVisionOneTrustConfig returns the configuration of a VisionOne trust. The lists are
+// given as HCL expressions.
+func testAccVisionOneTrustConfig(fingerprints, systemPolicyNames, customPolicyNames, roleDescription string) string {
+	return fmt.Sprintf(`
+resource "alicloudsecurity_visionone_trust" "test" {
+  oidc_fingerprints    = %s
+  system_policy_names  = %s
+  custom_policy_names  = %s
+  role_description     = %q
+  max_session_duration = 7200
+}
+`, fingerprints, systemPolicyNames, customPolicyNames, roleDescription)
+}
+
+// testAccCheckVisionOneTrustDestroyed checks that the default role and OIDC provider of the
+// VisionOne trust are deleted from the fake AliCloud server.
+func testAccCheckVisionOneTrustDestroyed(ali *fakeAliCloudServer) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if ali.Role("trendmicro-visionone-role") != nil {
+			return fmt.Errorf("role trendmicro-visionone-role still exists")
+		}
+		if ali.OidcProvider("trendmicro-visionone") != nil {
+			return fmt.Errorf("OIDC provider trendmicro-visionone still exists")
+		}
+		return nil
+	}
+}
+
+// testAccCheckVisionOneTrustPolicies checks the policies attached to the role of the fake
+// AliCloud server.
+func testAccCheckVisionOneTrustPolicies(ali *fakeAliCloudServer, expected ...fakeAliCloudPolicy) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		role := ali.Role("trendmicro-visionone-role")
+		if role == nil {
+			return fmt.Errorf("role trendmicro-visionone-role does not exist")
+		}
+		if len(role.Policies) != len(expected) {
+			return fmt.Errorf("expected %d policies, got %v", len(expected), role.Policies)
+		}
+		for _, policy := range expected {
+			if !role.Policies[policy] {
+				return fmt.Errorf("expected %s policy %s to be attached, got %v", policy.Type, policy.Name, role.Policies)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccVisionOneTrustResource(t *testing.T) {
+	cam := newFakeCamServer(t)
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	providerConfig := cam.ProviderConfig("automation", ali.ProviderConfig())
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVisionOneTrustDestroyed(ali),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccVisionOneTrustConfig(`["fingerprint-1"]`, `["ReadOnlyAccess"]`, `null`, "created"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "account_id", fakeStsAccountId),
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "role_arn", common.BuildRoleArn(fakeStsAccountId, "trendmicro-visionone-role")),
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "oidc_provider_id", "trendmicro-visionone"),
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "oidc_provider_arn", common.BuildOidcProviderArn(fakeStsAccountId, "trendmicro-visionone")),
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "oidc_issuer_url", common.VisionOneOidcIssuerUrl(fakeCamRegion)),
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "oidc_client_ids.#", "1"),
+					resource.TestCheckTypeSetElemAttr("alicloudsecurity_visionone_trust.test", "oidc_client_ids.*", fakeCamBusinessId),
+					resource.TestCheckResourceAttrSet("alicloudsecurity_visionone_trust.test", "trust_policy"),
+					testAccCheckVisionOneTrustPolicies(ali, fakeAliCloudPolicy{Type: policyTypeSystem, Name: "ReadOnlyAccess"}),
+					func(*terraform.State) error {
+						if role := ali.Role("trendmicro-visionone-role"); role.Description != "created" || role.MaxSessionDuration != 7200 {
+							return fmt.Errorf("unexpected role %+v", role)
+						}
+						return nil
+					},
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "alicloudsecurity_visionone_trust.test",
+				ImportState:                          true,
+				ImportStateId:                        "trendmicro-visionone-role:trendmicro-visionone",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "role_arn",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccVisionOneTrustConfig(`["fingerprint-2"]`, `["AliyunActionTrailReadOnlyAccess"]`, `["visionone-custom"]`, "updated"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("alicloudsecurity_visionone_trust.test", "role_description", "updated"),
+					resource.TestCheckTypeSetElemAttr("alicloudsecurity_visionone_trust.test", "oidc_fingerprints.*", "fingerprint-2"),
+					testAccCheckVisionOneTrustPolicies(ali,
+						fakeAliCloudPolicy{Type: policyTypeSystem, Name: "AliyunActionTrailReadOnlyAccess"},
+						fakeAliCloudPolicy{Type: policyTypeCustom, Name: "visionone-custom"}),
+					func(*terraform.State) error {
+						provider := ali.OidcProvider("trendmicro-visionone")
+						if provider == nil || strings.Join(provider.Fingerprints, ",") != "fingerprint-2" {
+							return fmt.Errorf("expected the fingerprints to be replaced, got %+v", provider)
+						}
+						if role := ali.Role("trendmicro-visionone-role"); role.Description != "updated" {
+							return fmt.Errorf("expected the role description to be updated, got %q", role.Description)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccVisionOneTrustResourceCreateFailure(t *testing.T) {
+	tests := map[string]struct {
+		action      string
+		expectError *regexp.Regexp
+	}{
+		// The OIDC provider is deleted when the role cannot be created
+		"create role": {
+			action:      "CreateRole",
+			expectError: regexp.MustCompile(`Create Role Error`),
+		},
+		// The trust is kept in the state, tainted, and destroyed at the end of the test
+		"attach policy": {
+			action:      "AttachPolicyToRole",
+			expectError: regexp.MustCompile(`Attach Policy Error`),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cam := newFakeCamServer(t)
+			ali := newFakeAliCloudServer(t, fakeStsAccountId)
+			ali.InjectFault(tt.action, http.StatusForbidden, "NoPermission")
+
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				CheckDestroy:             testAccCheckVisionOneTrustDestroyed(ali),
+				Steps: []resource.TestStep{
+					{
+						Config:      cam.ProviderConfig("automation", ali.ProviderConfig()) + testAccVisionOneTrustConfig(`["fingerprint-1"]`, `["ReadOnlyAccess"]`, `null`, "created"),
+						ExpectError: tt.expectError,
+					},
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"terraform-provider-alicloudsecurity/internal/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// newTestVisionOneTrustResource returns a VisionOne trust resource whose AliCloud clients send
+// requests to the given fake server.
+func newTestVisionOneTrustResource(t *testing.T, ali *fakeAliCloudServer) *visionOneTrustResource {
+	t.Helper()
+
+	return &visionOneTrustResource{
+		cam: newTestFakeCamClient(t, newFakeCamServer(t), "automation"),
+		alicloud: &common.AliCloudClients{
+			Config: &common.AliCloudClientConfig{
+				AccessKey:       "test-access-key",
+				AccessKeySecret: "test-access-secret",
+				Region:          "cn-hangzhou",
+				StsEndpoint:     ali.URL,
+				RamEndpoint:     ali.URL,
+				ImsEndpoint:     ali.URL,
+			},
+		},
+	}
+}
+
+// newTestVisionOneTrustPlan returns a plan to create a VisionOne trust with the default names,
+// the given fingerprints and the given system policies.
+func newTestVisionOneTrustPlan(t *testing.T, r *visionOneTrustResource, fingerprints, systemPolicyNames []string) tfsdk.Plan {
+	t.Helper()
+
+	ctx := context.Background()
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	var diags diag.Diagnostics
+	plan := tfsdk.Plan{Schema: schemaResp.Schema}
+	diags.Append(plan.Set(ctx, &visionOneTrustResourceModel{
+		OidcProviderName:   types.StringValue("trendmicro-visionone"),
+		OidcIssuerUrl:      types.StringUnknown(),
+		OidcFingerprints:   stringsToSet(ctx, fingerprints, &diags),
+		OidcClientIds:      types.SetUnknown(types.StringType),
+		RoleName:           types.StringValue("trendmicro-visionone-role"),
+		RoleDescription:    types.StringValue("test"),
+		MaxSessionDuration: types.Int64Value(3600),
+		SystemPolicyNames:  stringsToSet(ctx, systemPolicyNames, &diags),
+		CustomPolicyNames:  types.SetNull(types.StringType),
+		AccountId:          types.StringUnknown(),
+		RoleArn:            types.StringUnknown(),
+		OidcProviderId:     types.StringUnknown(),
+		OidcProviderArn:    types.StringUnknown(),
+		TrustPolicy:        types.StringUnknown(),
+	})...)
+	if diags.HasError() {
+		t.Fatalf("failed to set plan: %v", diags)
+	}
+	return plan
+}
+
+func TestVisionOneTrustResourceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	r := newTestVisionOneTrustResource(t, ali)
+
+	plan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-1"}, []string{"ReadOnlyAccess"})
+	createResp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected create diagnostics: %v", createResp.Diagnostics)
+	}
+
+	var created visionOneTrustResourceModel
+	createResp.State.Get(ctx, &created)
+	if created.RoleArn.ValueString() != common.BuildRoleArn(fakeStsAccountId, "trendmicro-visionone-role") {
+		t.Errorf("unexpected role ARN %s", created.RoleArn.ValueString())
+	}
+	if created.AccountId.ValueString() != fakeStsAccountId {
+		t.Errorf("unexpected account ID %s", created.AccountId.ValueString())
+	}
+	provider := ali.OidcProvider("trendmicro-visionone")
+	if provider == nil || !slices.Equal(provider.ClientIds, []string{fakeCamBusinessId}) || provider.IssuerUrl != common.VisionOneOidcIssuerUrl(fakeCamRegion) {
+		t.Fatalf("unexpected OIDC provider %+v", provider)
+	}
+	if role := ali.Role("trendmicro-visionone-role"); role == nil || !role.Policies[fakeAliCloudPolicy{Type: policyTypeSystem, Name: "ReadOnlyAccess"}] {
+		t.Fatalf("expected the role to be created with the ReadOnlyAccess policy, got %+v", role)
+	}
+
+	// Replace the fingerprint and the system policy
+	var updated visionOneTrustResourceModel
+	updatePlan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-2"}, []string{"AliyunActionTrailReadOnlyAccess"})
+	updatePlan.Get(ctx, &updated)
+	updated.OidcIssuerUrl = created.OidcIssuerUrl
+	updated.OidcClientIds = created.OidcClientIds
+	updated.AccountId = created.AccountId
+	updated.RoleArn = created.RoleArn
+	updated.OidcProviderId = created.OidcProviderId
+	updated.OidcProviderArn = created.OidcProviderArn
+	updatePlan.Set(ctx, &updated)
+
+	updateResp := &resource.UpdateResponse{State: createResp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: updatePlan, State: createResp.State}, updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected update diagnostics: %v", updateResp.Diagnostics)
+	}
+	if provider := ali.OidcProvider("trendmicro-visionone"); !slices.Equal(provider.Fingerprints, []string{"fingerprint-2"}) {
+		t.Errorf("expected the fingerprints to be replaced, got %v", provider.Fingerprints)
+	}
+	role := ali.Role("trendmicro-visionone-role")
+	if len(role.Policies) != 1 || !role.Policies[fakeAliCloudPolicy{Type: policyTypeSystem, Name: "AliyunActionTrailReadOnlyAccess"}] {
+		t.Errorf("expected the system policy to be replaced, got %v", role.Policies)
+	}
+
+	// Read the state back as it was updated
+	readResp := &resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected read diagnostics: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.Equal(updateResp.State.Raw) {
+		t.Errorf("expected the read state to match the updated state:\n got: %v\nwant: %v", readResp.State.Raw, updateResp.State.Raw)
+	}
+
+	// Import the trust as it was updated
+	importResp := &resource.ImportStateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	importResp.State.Raw = tftypes.NewValue(plan.Schema.Type().TerraformType(ctx), nil)
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "trendmicro-visionone-role:trendmicro-visionone"}, importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected import diagnostics: %v", importResp.Diagnostics)
+	}
+	readResp = &resource.ReadResponse{State: importResp.State}
+	r.Read(ctx, resource.ReadRequest{State: importResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected read diagnostics: %v", readResp.Diagnostics)
+	}
+	if !readResp.State.Raw.Equal(updateResp.State.Raw) {
+		t.Errorf("expected the imported state to match the updated state:\n got: %v\nwant: %v", readResp.State.Raw, updateResp.State.Raw)
+	}
+
+	deleteResp := &resource.DeleteResponse{State: updateResp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected delete diagnostics: %v", deleteResp.Diagnostics)
+	}
+	if ali.Role("trendmicro-visionone-role") != nil || ali.OidcProvider("trendmicro-visionone") != nil {
+		t.Fatal("expected the role and the OIDC provider to be deleted")
+	}
+}
+
+func TestVisionOneTrustResourceCreateRoleFailureDeletesOidcProvider(t *testing.T) {
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	ali.InjectFault("CreateRole", http.StatusForbidden, "NoPermission")
+	r := newTestVisionOneTrustResource(t, ali)
+
+	plan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-1"}, []string{"ReadOnlyAccess"})
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(context.Background(), resource.CreateRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Create Role Error" {
+		t.Fatalf("expected a create role error, got %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("expected no state")
+	}
+	if ali.OidcProvider("trendmicro-visionone") != nil {
+		t.Error("expected the OIDC provider to be deleted")
+	}
+}
+
+func TestVisionOneTrustResourceAttachFailureKeepsState(t *testing.T) {
+	ctx := context.Background()
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	ali.InjectFault("AttachPolicyToRole", http.StatusForbidden, "NoPermission")
+	r := newTestVisionOneTrustResource(t, ali)
+
+	plan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-1"}, []string{"ReadOnlyAccess"})
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Attach Policy Error" {
+		t.Fatalf("expected an attach policy error, got %v", resp.Diagnostics)
+	}
+	// The state is kept so that Terraform taints the trust and destroys it
+	if resp.State.Raw.IsNull() {
+		t.Fatal("expected the trust to be kept in the state")
+	}
+
+	deleteResp := &resource.DeleteResponse{State: resp.State}
+	r.Delete(ctx, resource.DeleteRequest{State: resp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected delete diagnostics: %v", deleteResp.Diagnostics)
+	}
+	if ali.Role("trendmicro-visionone-role") != nil || ali.OidcProvider("trendmicro-visionone") != nil {
+		t.Fatal("expected the role and the OIDC provider to be deleted")
+	}
+}
+
+func TestVisionOneTrustResourceImportStateInvalidId(t *testing.T) {
+	r := &visionOneTrustResource{}
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, schemaResp)
+
+	for _, id := range []string{"", "trendmicro-visionone-role", ":trendmicro-visionone", "trendmicro-visionone-role:"} {
+		resp := &resource.ImportStateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+		r.ImportState(context.Background(), resource.ImportStateRequest{ID: id}, resp)
+		if !resp.Diagnostics.HasError() {
+			t.Errorf("expected an error for import ID %q", id)
+		}
+	}
+}
+
+func TestVisionOneTrustResourceCreateTrustsConfiguredOidcSettings(t *testing.T) {
+	ctx := context.Background()
+	ali := newFakeAliCloudServer(t, fakeStsAccountId)
+	r := newTestVisionOneTrustResource(t, ali)
+
+	var model visionOneTrustResourceModel
+	plan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-1"}, []string{"ReadOnlyAccess"})
+	plan.Get(ctx, &model)
+	var diags diag.Diagnostics
+	model.OidcIssuerUrl = types.StringValue("https://issuer.example.com")
+	model.OidcClientIds = stringsToSet(ctx, []string{"client-1"}, &diags)
+	plan.Set(ctx, &model)
+
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: plan.Schema}}
+	r.Create(ctx, resource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected create diagnostics: %v", resp.Diagnostics)
+	}
+
+	expected, err := common.BuildOidcTrustPolicy(fakeStsAccountId, "trendmicro-visionone", "https://issuer.example.com", []string{"client-1"})
+	if err != nil {
+		t.Fatalf("failed to build the trust policy: %v", err)
+	}
+	if role := ali.Role("trendmicro-visionone-role"); role == nil || role.TrustPolicy != expected {
+		t.Fatalf("expected the role to trust the configured issuer and client IDs, got %+v", role)
+	}
+}
+
+func TestVisionOneTrustResourceModifyPlanFollowsProvider(t *testing.T) {
+	ctx := context.Background()
+	r := newTestVisionOneTrustResource(t, newFakeAliCloudServer(t, fakeStsAccountId))
+
+	// A trust created for another business and region of the provider
+	var diags diag.Diagnostics
+	var model visionOneTrustResourceModel
+	plan := newTestVisionOneTrustPlan(t, r, []string{"fingerprint-1"}, []string{"ReadOnlyAccess"})
+	plan.Get(ctx, &model)
+	model.OidcIssuerUrl = types.StringValue(common.VisionOneOidcIssuerUrl("eu"))
+	model.OidcClientIds = stringsToSet(ctx, []string{"other-business"}, &diags)
+	plan.Set(ctx, &model)
+	state := tfsdk.State{Schema: plan.Schema, Raw: plan.Raw}
+
+	model.OidcIssuerUrl = types.StringNull()
+	model.OidcClientIds = types.SetNull(types.StringType)
+	config := tfsdk.Plan{Schema: plan.Schema}
+	config.Set(ctx, &model)
+
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: plan.Schema, Raw: config.Raw},
+		Plan:   plan,
+		State:  state,
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var planned visionOneTrustResourceModel
+	resp.Plan.Get(ctx, &planned)
+	if planned.OidcIssuerUrl.ValueString() != common.VisionOneOidcIssuerUrl(fakeCamRegion) {
+		t.Errorf("expected the issuer of the provider region, got %s", planned.OidcIssuerUrl)
+	}
+	if clientIds := setToStrings(ctx, planned.OidcClientIds, &diags); !slices.Equal(clientIds, []string{fakeCamBusinessId}) {
+		t.Errorf("expected the business ID of the provider, got %v", clientIds)
+	}
+	if len(resp.RequiresReplace) != 1 || !resp.RequiresReplace[0].Equal(path.Root("oidc_issuer_url")) {
+		t.Errorf("expected a new issuer to replace the trust, got %v", resp.RequiresReplace)
+	}
+}